@@ -0,0 +1,74 @@
+package reactor
+
+import (
+	"math"
+
+	"github.com/ewancook/reactor/thermo"
+)
+
+// elementFlows returns the flow of each element (mol/s) carried by flows,
+// which are ordered as the species of m.
+func (m mechanism) elementFlows(flows []float64) map[string]float64 {
+	elements := map[string]float64{}
+	for i, compound := range m.species {
+		for _, element := range thermo.Elements {
+			elements[element] += thermo.Atoms(compound, element) * flows[i]
+		}
+	}
+	return elements
+}
+
+// elementClosure returns the largest relative deviation of any element flow
+// from its inlet value over every step of a transposed solver table, along
+// with the offending element.
+func (m mechanism) elementClosure(yValues [][]float64) (float64, string) {
+	flows := make([]float64, len(m.species))
+	column := func(step int) []float64 {
+		for i := range flows {
+			flows[i] = yValues[i][step]
+		}
+		return flows
+	}
+	inlet := m.elementFlows(column(0))
+	var maxError float64
+	var worst string
+	for step := range yValues[0] {
+		for element, flow := range m.elementFlows(column(step)) {
+			if inlet[element] == 0 {
+				continue
+			}
+			if e := math.Abs(flow-inlet[element]) / inlet[element]; e > maxError {
+				maxError, worst = e, element
+			}
+		}
+	}
+	return maxError, worst
+}
+
+// enthalpyFlow returns the total enthalpy (kJ/s) of flows at temperature T,
+// including heats of formation.
+func enthalpyFlow(species []string, flows []float64, T float64) float64 {
+	var H float64
+	for i, compound := range species {
+		H += thermo.Enthalpy(compound, T) * flows[i]
+	}
+	return H
+}
+
+// energyClosure returns the imbalance between the enthalpy gained by the
+// process gas and the heat supplied through the wall, relative to the sum of
+// the inlet sensible heat (above 298.15 K) and the wall duty.
+func energyClosure(species []string, inlet, outlet []float64, Tin, Tout, duty float64) float64 {
+	gained := enthalpyFlow(species, outlet, Tout) - enthalpyFlow(species, inlet, Tin)
+	scale := enthalpyFlow(species, inlet, Tin) - enthalpyFlow(species, inlet, 298.15) + math.Abs(duty)
+	return math.Abs(gained-duty) / scale
+}
+
+// trapezoid integrates the samples y(x).
+func trapezoid(x, y []float64) float64 {
+	var sum float64
+	for i := 1; i < len(x); i++ {
+		sum += (x[i] - x[i-1]) * (y[i] + y[i-1]) / 2
+	}
+	return sum
+}
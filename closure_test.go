@@ -0,0 +1,37 @@
+package reactor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ewancook/reactor/units"
+)
+
+func TestMechanismsValidate(t *testing.T) {
+	for _, mech := range []mechanism{steamReforming, ammoniaCracking} {
+		if err := mech.validate(); err != nil {
+			t.Error(err)
+		}
+	}
+	typo := mechanism{keyName: "Typo", pressure: units.Bar, reactions: []reaction{
+		{map[string]float64{"CH4": -1, "H2O": -1, "CO": 1, "H2": 2}},
+	}}
+	if typo.validate() == nil {
+		t.Error("expected an error for unbalanced stoichiometry")
+	}
+	if (mechanism{keyName: "Undeclared"}).validate() == nil {
+		t.Error("expected an error for a mechanism without a pressure unit")
+	}
+}
+
+func TestElementClosure(t *testing.T) {
+	// CH4 + H2O -> CO + 3H2 at half conversion, with an H2 typo at the outlet
+	yValues := [][]float64{{0, 0.5}, {0, 1.5}, {1, 0.5}, {0, 0}, {1, 0.5}, {0, 0}}
+	if e, _ := steamReforming.elementClosure(yValues); e > 1e-12 {
+		t.Errorf("expected closed balance; got %e", e)
+	}
+	yValues[1][1] = 1.4
+	if e, element := steamReforming.elementClosure(yValues); element != "H" || math.Abs(e-0.2/6) > 1e-12 {
+		t.Errorf("expected H closure error of %e; got %e (%s)", 0.2/6, e, element)
+	}
+}
@@ -0,0 +1,544 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/cpmech/gosl/plt"
+	"github.com/ewancook/reactor"
+)
+
+func main() {
+	c := reactor.DefaultConfig()
+	flowCH4 := flag.Float64("CH4", c.Feed["CH4"], "initial flow of methane (mol/s)")
+	flowH2 := flag.Float64("H2", c.Feed["H2"], "initial flow of hydrogen (mol/s)")
+	flowCO := flag.Float64("CO", c.Feed["CO"], "initial flow of carbon monoxide (mol/s)")
+	flowCO2 := flag.Float64("CO2", c.Feed["CO2"], "initial flow of carbon dioxide (mol/s)")
+	flowH2O := flag.Float64("H2O", c.Feed["H2O"], "initial flow of steam (mol/s)")
+	flowC2H6 := flag.Float64("C2H6", c.Feed["C2H6"], "initial flow of ethane (mol/s)")
+	flowNH3 := flag.Float64("NH3", 100, "initial flow of ammonia in cracking mode (mol/s)")
+	flowN2 := flag.Float64("N2", 0, "initial flow of nitrogen in cracking mode (mol/s)")
+	flag.Float64Var(&c.Gas.Density, "density", c.Gas.Density, "gas density (kg/m^3)")
+	flag.Float64Var(&c.Heating.U, "U", c.Heating.U, "heat transfer coefficient (W/Km^2)")
+	flag.Float64Var(&c.T, "T", c.T, "initial reactor temperature (K)")
+	flag.Float64Var(&c.Heating.Talpha, "Talpha", c.Heating.Talpha, "heating gas temperature, Tα (K)")
+	flag.Float64Var(&c.P, "P", c.P, "initial reactor pressure (kPa)")
+	flag.Float64Var(&c.Geometry.D, "D", c.Geometry.D, "reactor diameter (m)")
+	flag.Float64Var(&c.Catalyst.Voidage, "voidage", c.Catalyst.Voidage, "bed voidage (ϕ)")
+	flag.Float64Var(&c.Gas.Viscosity, "viscosity", c.Gas.Viscosity, "gas viscosity (μ)")
+	flag.Float64Var(&c.Gas.Conductivity, "conductivity", c.Gas.Conductivity, "gas thermal conductivity, for gas-solid heat transfer (W/mK)")
+	flag.Float64Var(&c.Gas.Diffusivity, "diffusivity", c.Gas.Diffusivity, "gas diffusivity of the reactants, for gas-solid mass transfer (m^2/s)")
+	flag.Float64Var(&c.Catalyst.Dp, "Dp", c.Catalyst.Dp, "particle diameter (m)")
+	flag.Float64Var(&c.Catalyst.Density, "catalyst-density", c.Catalyst.Density, "catalyst-density (kg/m^3)")
+	flag.Float64Var(&c.Geometry.Length, "l", c.Geometry.Length, "tube length (m)")
+	flag.Float64Var(&c.Geometry.Tubes, "tubes", c.Geometry.Tubes, "number of tubes")
+	nograph := flag.Bool("nograph", false, "stops plotting of graphs")
+	mode := flag.String("mode", string(reactor.Reforming), "reactor mode: reforming, methanation (coolant held at Talpha) or cracking")
+	flag.BoolVar(&c.Heating.Adiabatic, "adiabatic", false, "removes heat transfer through the tube wall")
+	counterCurrent := flag.Bool("counter-current", false, "heating gas enters at the process outlet, at Talpha")
+	closureTol := flag.Float64("closure-tol", 1e-3, "relative tolerance on element and energy balance closure")
+	strict := flag.Bool("strict", false, "fails, rather than warns, when closure exceeds closure-tol")
+	heating := flag.String("heating", string(reactor.HeatingGas), "heating mode: gas, radiation (from the heating gas), wall-temperature or heat-flux, the latter two read from heating-profile")
+	heatingProfile := flag.String("heating-profile", "", "CSV of axial position (m) against wall temperature (K) or heat flux (W/m^2)")
+	flag.Float64Var(&c.Furnace.BeamLength, "beam-length", c.Furnace.BeamLength, "mean beam length of the furnace flue gas for radiation heating (m)")
+	flag.Float64Var(&c.Furnace.TubeEmissivity, "tube-emissivity", c.Furnace.TubeEmissivity, "emissivity of the tube surface for radiation heating")
+	correlation := flag.String("wall-correlation", "", "finds U along the tube from a bed-side wall correlation: leva, li-finlayson or dixon")
+	flag.Float64Var(&c.Wall.Thickness, "wall-thickness", c.Wall.Thickness, "tube wall thickness for wall-correlation (m)")
+	flag.Float64Var(&c.Wall.Conductivity, "wall-conductivity", c.Wall.Conductivity, "tube wall thermal conductivity for wall-correlation (W/mK)")
+	flag.Float64Var(&c.Heating.Outside, "ho", c.Heating.Outside, "heating gas to outer wall coefficient for wall-correlation (W/Km^2)")
+	service := flag.Float64("service", 0, "hours the tubes have served at these conditions, for the remaining creep life")
+	flag.StringVar(&c.Solver.Method, "method", c.Solver.Method, "integration method of gosl/ode, e.g. radau5, dopri5, dopri8 or bweuler")
+	flag.Float64Var(&c.Solver.Atol, "atol", 0, "absolute tolerance of the integrator (0 takes rtol, or keeps its default without rtol)")
+	flag.Float64Var(&c.Solver.Rtol, "rtol", 0, "relative tolerance of the integrator (0 takes atol, or keeps its default without atol)")
+	flag.Float64Var(&c.Solver.InitialStep, "h0", 0, "initial step of the integrator (kg, or s in transients; 0 lets it choose)")
+	flag.IntVar(&c.Solver.MaxSteps, "max-steps", 0, "maximum steps per integration (0 keeps the default)")
+	flag.BoolVar(&c.Solver.AnalyticJacobian, "analytic-jacobian", false, "use the analytical Jacobian of the plug flow bed in place of finite differences")
+	axis := flag.String("axis", "length", "x-axis of the plots: length (m), mass (of catalyst, kg) or time (gas residence, s)")
+	zones := flag.String("zones", "", "CSV of tube zones from the inlet with columns length, D, density, voidage, Dp, activity and inert, in place of l")
+	groups := flag.String("groups", "", "CSV of tube groups with columns name, tubes, flux (multiplier), density, voidage and Dp; solves the flow split between them")
+	bed := flag.String("bed", string(c.Bed), "bed model: plug-flow, radial (two-dimensional), heterogeneous (separate catalyst temperature and surface composition) or dispersion (axial)")
+	flag.IntVar(&c.Radial.Nodes, "radial-nodes", c.Radial.Nodes, "rings in the radial bed model")
+	flag.Float64Var(&c.Radial.Conductivity, "lambda-er", c.Radial.Conductivity, "effective radial conductivity of the radial bed model (W/mK)")
+	flag.Float64Var(&c.Radial.Hw, "hw", c.Radial.Hw, "bed-side wall heat transfer coefficient of the radial bed model (W/Km^2)")
+	flag.IntVar(&c.Dispersion.Cells, "cells", c.Dispersion.Cells, "finite volumes in the dispersion bed model")
+	schedule := flag.String("schedule", "", "CSV of time (s, headed t) against inputs such as CH4, T, Talpha or flue:N2; runs a transient simulation")
+	flag.Float64Var(&c.Transient.Duration, "duration", c.Transient.Duration, "length of a transient simulation (s)")
+	flag.Float64Var(&c.Transient.Interval, "interval", c.Transient.Interval, "time between saved profiles of a transient simulation (s)")
+	history := flag.String("history", "", "writes the profiles of a transient simulation as CSV to a file (- for stdout)")
+	profile := flag.String("profile", "", "writes per-step profiles, including reaction rates, heats and wall flux, as CSV to a file (- for stdout)")
+	sensitivity := flag.String("sensitivity", "", "comma-separated inputs, e.g. U,Talpha,Dp,k1,CH4, whose normalised sensitivity coefficients are found along the tube")
+	sensitivityCSV := flag.String("sensitivity-csv", "", "writes the sensitivity coefficients along the tube as CSV to a file (- for stdout)")
+	stopConversion := flag.Float64("stop-conversion", 0, "ends the tube where the conversion reaches this target (0 for none)")
+	maxT := flag.Float64("max-T", 0, "reports where the process gas exceeds this temperature (K; 0 for none)")
+	maxTMT := flag.Float64("max-tmt", 0, "reports where the tube wall exceeds this temperature (K; 0 for none)")
+	minP := flag.Float64("min-P", 0, "reports where the pressure falls below this value (kPa; 0 for none)")
+	carbon := flag.Bool("carbon", false, "reports where carbon formation becomes favourable")
+	stopAtLimits := flag.Bool("stop-at-limits", false, "ends the tube at the first of max-T, max-tmt, min-P and carbon rather than reporting them")
+
+	// flue gases
+	flueN2 := flag.Float64("flueN2", c.Flue["N2"], "flue flowrate of nitrogen (mol/s)")
+	flueCO2 := flag.Float64("flueCO2", c.Flue["CO2"], "flue flowrate of carbon dioxide (mol/s)")
+	flueH2O := flag.Float64("flueH2", c.Flue["H2O"], "flue flowrate of steam (mol/s)")
+	flueO2 := flag.Float64("flueCH4", c.Flue["O2"], "flue flowrate of oxygen (mol/s)")
+
+	// "reactor sweep" runs the configuration of the flags above over grids,
+	// taking its own flags as well
+	sweepFlags := flag.NewFlagSet("sweep", flag.ExitOnError)
+	var grids gridFlags
+	sweepFlags.Var(&grids, "grid", "an input and its values, e.g. T=800:900:25 (start:stop:step) or tubes=150,200; repeat for a grid of every combination. "+
+		"Inputs are a feed species, T, P, Talpha, U, ho, Dp, voidage, tubes, D, l, wall-thickness, wall-conductivity, flue:N2 (or another heating gas species) or k1 (or another reaction's rate factor)")
+	workers := sweepFlags.Int("workers", 0, "cases simulated at once (0 for one per CPU)")
+	format := sweepFlags.String("format", "csv", "the table of outlets: csv or json")
+	output := sweepFlags.String("o", "-", "writes the table of outlets to a file (- for stdout)")
+	flag.VisitAll(func(f *flag.Flag) {
+		sweepFlags.Var(f.Value, f.Name, f.Usage)
+	})
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: reactor [flags]\n       reactor sweep -grid input=values [-grid ...] [flags] (see reactor sweep -h)\n")
+		flag.PrintDefaults()
+	}
+
+	sweeping := len(os.Args) > 1 && os.Args[1] == "sweep"
+	if sweeping {
+		sweepFlags.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
+	}
+
+	switch *axis {
+	case "length", "mass", "time":
+	default:
+		log.Fatalf("unknown axis %q", *axis)
+	}
+
+	c.Mode = reactor.Mode(*mode)
+	c.Heating.Mode = reactor.HeatingMode(*heating)
+	c.Heating.Correlation = reactor.WallCorrelation(*correlation)
+	c.Bed = reactor.BedModel(*bed)
+	if *heatingProfile != "" {
+		file, err := os.Open(*heatingProfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		c.Heating.Profile, err = reactor.ReadTable(file)
+		file.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *zones != "" {
+		file, err := os.Open(*zones)
+		if err != nil {
+			log.Fatal(err)
+		}
+		c.Zones, err = reactor.ReadZones(file)
+		file.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *counterCurrent {
+		c.Heating.Arrangement = reactor.CounterCurrent
+	}
+	c.Feed = map[string]float64{
+		"CO":   *flowCO,
+		"H2":   *flowH2,
+		"CH4":  *flowCH4,
+		"CO2":  *flowCO2,
+		"H2O":  *flowH2O,
+		"C2H6": *flowC2H6,
+	}
+	if c.Mode == reactor.Cracking {
+		c.Feed = map[string]float64{"NH3": *flowNH3, "N2": *flowN2, "H2": *flowH2}
+	}
+	c.Flue = map[string]float64{"N2": *flueN2, "CO2": *flueCO2, "H2O": *flueH2O, "O2": *flueO2}
+
+	if *stopConversion > 0 {
+		e := reactor.ConversionAbove(*stopConversion)
+		e.Stop = true
+		c.Events = append(c.Events, e)
+	}
+	var limits []reactor.Event
+	if *maxT > 0 {
+		limits = append(limits, reactor.TemperatureAbove(*maxT))
+	}
+	if *maxTMT > 0 {
+		limits = append(limits, reactor.WallTemperatureAbove(*maxTMT))
+	}
+	if *minP > 0 {
+		limits = append(limits, reactor.PressureBelow(*minP))
+	}
+	if *carbon {
+		limits = append(limits, reactor.CarbonFormation())
+	}
+	for _, e := range limits {
+		e.Stop = *stopAtLimits
+		c.Events = append(c.Events, e)
+	}
+
+	if *schedule != "" {
+		transient(c, *schedule, *history)
+		return
+	}
+
+	if *groups != "" {
+		split(c, *groups)
+		return
+	}
+
+	if sweeping {
+		sweep(c, grids, *workers, *format, *output)
+		return
+	}
+
+	var p *reactor.Profile
+	var sensitivities []reactor.Sensitivity
+	var err error
+	if *sensitivity != "" {
+		p, sensitivities, err = reactor.Sensitivities(context.Background(), c, strings.Split(*sensitivity, ","))
+	} else {
+		p, err = reactor.Simulate(context.Background(), c)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	last := len(p.W) - 1
+	conversions := p.Conversion()
+	pressureDrop := c.P - p.P[last]
+
+	if c.Mode == reactor.Methanation {
+		fmt.Printf("tubes: %.0f; outlet CO (ppmv dry): %.3g; outlet CO2 (ppmv dry): %.3g; temperature rise (K): %.2f; peak temperature %2f (K); pressure drop (kPa): %.4f\n",
+			c.Geometry.Tubes, p.DryPPM("CO")[last], p.DryPPM("CO2")[last], p.T[last]-c.T, maxValue(p.T), pressureDrop)
+	} else {
+		fmt.Printf("tubes: %.0f; conversion: %.2f; pressure drop (kPa): %.4f; outlet temperature %2f (K)\n", c.Geometry.Tubes, conversions[last], pressureDrop, p.T[last])
+	}
+
+	if c.Heating.Arrangement == reactor.CounterCurrent {
+		fmt.Printf("heating gas outlet temperature %2f (K)", p.Talpha[0])
+		if p.ShootingIterations > 0 {
+			fmt.Printf("; shooting iterations: %d", p.ShootingIterations)
+		}
+		fmt.Println()
+	}
+
+	if p.RuptureLife != nil {
+		tmt, k := p.MaxTMT()
+		life, weakest := p.RemainingLife(*service)
+		fmt.Printf("max TMT %2f (K) at %.2f m; remaining creep life (h): %.3g at %.2f m\n", tmt, p.Z[k], life, p.Z[weakest])
+	}
+	if p.Resistances != nil {
+		for _, k := range []int{0, last} {
+			r := p.Resistances[k]
+			fmt.Printf("%s U (W/Km^2): %.1f; resistances (m^2K/W) bed: %.3g; wall: %.3g; outside: %.3g\n",
+				map[int]string{0: "inlet", last: "outlet"}[k], 1/r.Total(), r.Bed, r.Wall, r.Outside)
+		}
+	}
+	if p.Ts != nil {
+		var maxDifference float64
+		for k := range p.W {
+			maxDifference = math.Max(maxDifference, math.Abs(p.T[k]-p.Ts[k]))
+		}
+		fmt.Printf("outlet catalyst temperature %2f (K); largest gas-catalyst difference (K): %.2f\n", p.Ts[last], maxDifference)
+	}
+	if p.RadialT != nil {
+		var maxDifference float64
+		for k := range p.W {
+			if d := p.RadialT[len(p.Radius)-1][k] - p.RadialT[0][k]; d > maxDifference {
+				maxDifference = d
+			}
+		}
+		fmt.Printf("outlet centre temperature %2f (K); outlet wall node temperature %2f (K); largest wall-centre difference (K): %.2f\n",
+			p.RadialT[0][last], p.RadialT[len(p.Radius)-1][last], maxDifference)
+	}
+
+	fmt.Printf("flows (mol/s)")
+	for _, compound := range p.Species {
+		fmt.Printf("; %s: %.2f", compound, p.Outlet(compound))
+	}
+	fmt.Println()
+
+	for _, o := range p.Events {
+		fmt.Printf("event: %s at %.3f m (%.4g kg)", o.Name, o.Z, o.W)
+		if o.Stop {
+			fmt.Printf("; tube ends here")
+		}
+		fmt.Println()
+	}
+
+	for _, s := range sensitivities {
+		fmt.Printf("sensitivity to %s (normalised): conversion: %.4f; outlet temperature: %.4f; pressure drop: %.4f\n",
+			s.Input, s.Conversion[last], s.T[last], s.PressureDrop[last])
+	}
+
+	fmt.Printf("max element closure error: %.2e %s; energy closure error: %.2e\n", p.ElementClosure, p.ClosureElement, p.EnergyClosure)
+	printStats(c.Solver.Method, p.Stats)
+	if p.ElementClosure > *closureTol || p.EnergyClosure > *closureTol {
+		message := fmt.Sprintf("balance closure exceeds tolerance of %.1e", *closureTol)
+		if *strict {
+			log.Fatal(message)
+		}
+		log.Print("warning: " + message)
+	}
+
+	write(*profile, p.WriteCSV)
+	write(*sensitivityCSV, func(w io.Writer) error {
+		return reactor.WriteSensitivitiesCSV(w, p, sensitivities)
+	})
+
+	if *nograph {
+		return
+	}
+
+	x, xLabel := p.Z, "Axial Position (m)"
+	switch *axis {
+	case "mass":
+		x, xLabel = p.W, "Catalyst (kg)"
+	case "time":
+		x, xLabel = p.Residence, "Residence Time (s)"
+	}
+
+	plt.Subplot(3, 3, 1)
+	if c.Mode == reactor.Methanation {
+		plt.Plot(x, p.DryPPM("CO"), &plt.A{L: "CO"})
+		plt.Plot(x, p.DryPPM("CO2"), &plt.A{L: "CO2"})
+		plt.Legend(nil)
+		plt.Grid(nil)
+		plt.SetLabels(xLabel, "ppmv (dry)", nil)
+	} else {
+		plt.Plot(x, conversions, nil)
+		plt.Grid(nil)
+		plt.SetLabels(xLabel, p.KeyReactant()+" Conversion", nil)
+	}
+
+	plt.Subplot(3, 3, 2)
+	plt.Plot(x, p.P, nil)
+	plt.SetTicksNormal()
+	plt.Grid(nil)
+	plt.SetLabels(xLabel, "Presssure (kPa)", nil)
+
+	plt.Subplot(3, 3, 3)
+	if p.OuterWall != nil {
+		plt.Plot(x, p.Talpha, &plt.A{L: "heating"})
+		plt.Plot(x, p.OuterWall, &plt.A{L: "outer wall"})
+		plt.Legend(nil)
+	} else {
+		plt.Plot(x, p.Talpha, nil)
+	}
+	plt.Grid(nil)
+	if c.Heating.Mode == reactor.WallTemperature {
+		plt.SetLabels(xLabel, "Wall Temperature (K)", nil)
+	} else {
+		plt.SetLabels(xLabel, "Talpha (K)", nil)
+	}
+
+	plt.Subplot(3, 3, 4)
+	if p.Ts != nil {
+		plt.Plot(x, p.T, &plt.A{L: "gas"})
+		plt.Plot(x, p.Ts, &plt.A{L: "catalyst"})
+		plt.Legend(nil)
+	} else {
+		plt.Plot(x, p.T, nil)
+	}
+	plt.Grid(nil)
+	plt.SetLabels(xLabel, "T (K)", nil)
+
+	if ethane, ok := p.Flows["C2H6"]; ok {
+		var ethaneConversions []float64
+		for _, e := range ethane {
+			ethaneConversions = append(ethaneConversions, 1-e/ethane[0])
+		}
+
+		plt.Subplot(3, 3, 5)
+		plt.Plot(x, ethaneConversions, nil)
+		plt.AxisYmin(0)
+		plt.Grid(nil)
+		plt.SetLabels(xLabel, "Ethane Conversion", nil)
+	}
+
+	if p.RadialT != nil {
+		var outlet []float64
+		for _, T := range p.RadialT {
+			outlet = append(outlet, T[last])
+		}
+		plt.Subplot(3, 3, 6)
+		plt.Plot(p.Radius, outlet, nil)
+		plt.Grid(nil)
+		plt.SetLabels("Radius (m)", "Outlet T (K)", nil)
+	}
+
+	plt.Subplot(3, 3, 7)
+	for _, r := range p.Reactions {
+		plt.Plot(x, r.Rate, &plt.A{L: r.Equation})
+	}
+	plt.Legend(nil)
+	plt.Grid(nil)
+	plt.SetLabels(xLabel, "Rate (mol/s/kg)", nil)
+
+	plt.Subplot(3, 3, 8)
+	for _, r := range p.Reactions {
+		plt.Plot(x, r.Heat, &plt.A{L: r.Equation})
+	}
+	plt.Legend(nil)
+	plt.Grid(nil)
+	plt.SetLabels(xLabel, "Heat Absorbed (W/kg)", nil)
+
+	plt.Subplot(3, 3, 9)
+	plt.Plot(x, p.WallFlux, nil)
+	plt.Grid(nil)
+	plt.SetLabels(xLabel, "Wall Heat Flux (W/m^2)", nil)
+
+	plt.Show()
+}
+
+// maxValue returns the largest element of values.
+func maxValue(values []float64) float64 {
+	max := values[0]
+	for _, v := range values[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// write calls writeCSV with the file named by path, or stdout for "-", and
+// does nothing for an empty path.
+func write(path string, writeCSV func(io.Writer) error) {
+	switch path {
+	case "":
+	case "-":
+		if err := writeCSV(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		file, err := os.Create(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := writeCSV(file); err != nil {
+			log.Fatal(err)
+		}
+		if err := file.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// transient runs a transient simulation of c with the inputs scheduled in the
+// named file, printing the outlet at each saved time.
+func transient(c reactor.Config, schedule, history string) {
+	file, err := os.Open(schedule)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c.Transient.Schedule, err = reactor.ReadSchedule(file)
+	file.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	h, err := reactor.SimulateTransient(context.Background(), c)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for k, p := range h.Profiles {
+		last := len(p.W) - 1
+		fmt.Printf("t (s): %.1f; conversion: %.3f; outlet temperature %2f (K); pressure drop (kPa): %.4f\n",
+			h.Times[k], p.Conversion()[last], p.T[last], p.P[0]-p.P[last])
+	}
+	printStats(c.Solver.Method, h.Stats)
+	write(history, h.WriteCSV)
+}
+
+// split simulates the tube groups read from path and prints each with the
+// spread between them.
+func split(c reactor.Config, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	groups, err := reactor.ReadGroups(file)
+	file.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := reactor.SimulateGroups(context.Background(), c, groups)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, g := range s.Groups {
+		p := s.Profiles[i]
+		last := len(p.W) - 1
+		fmt.Printf("group %s: tubes: %.0f; flow share: %.4f; conversion: %.2f; outlet temperature %2f (K)", g.Name, g.Tubes, s.Shares[i], p.Conversion()[last], p.T[last])
+		if tmt, k := p.MaxTMT(); k >= 0 {
+			fmt.Printf("; max TMT %2f (K)", tmt)
+		}
+		fmt.Println()
+	}
+	outletT, tmt := s.Spread()
+	fmt.Printf("header pressure drop (kPa): %.4f; split iterations: %d; outlet temperature spread (K): %.2f; TMT spread (K): %.2f\n",
+		c.P-s.Profiles[0].P[len(s.Profiles[0].P)-1], s.Iterations, outletT, tmt)
+}
+
+// gridFlags collects the grids of a sweep from repeated flags.
+type gridFlags []reactor.Grid
+
+func (g *gridFlags) String() string {
+	var names []string
+	for _, grid := range *g {
+		names = append(names, grid.Input)
+	}
+	return strings.Join(names, ",")
+}
+
+func (g *gridFlags) Set(s string) error {
+	grid, err := reactor.ParseGrid(s)
+	if err != nil {
+		return err
+	}
+	*g = append(*g, grid)
+	return nil
+}
+
+// sweep simulates c for every combination of the values of grids, writing
+// the outlet of each case to the named file as CSV or JSON.
+func sweep(c reactor.Config, grids []reactor.Grid, workers int, format, path string) {
+	if len(grids) == 0 {
+		log.Fatal("sweep needs at least one -grid")
+	}
+	s, err := reactor.SimulateSweep(context.Background(), c, grids, workers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var writeTable func(io.Writer) error
+	switch format {
+	case "csv":
+		writeTable = s.WriteCSV
+	case "json":
+		writeTable = s.WriteJSON
+	default:
+		log.Fatalf("unknown sweep format %q", format)
+	}
+	write(path, writeTable)
+	var failed int
+	for _, c := range s.Cases {
+		if c.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Printf("warning: %d of %d cases failed", failed, len(s.Cases))
+	}
+}
+
+// printStats prints the work of the integrator.
+func printStats(method string, s reactor.SolverStats) {
+	fmt.Printf("%s: integrations: %d; steps: %d (accepted: %d; rejected: %d); function evaluations: %d; jacobian evaluations: %d; decompositions: %d\n",
+		method, s.Integrations, s.Steps, s.Accepted, s.Rejected, s.FunctionEvaluations, s.JacobianEvaluations, s.Decompositions)
+}
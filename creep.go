@@ -0,0 +1,48 @@
+package reactor
+
+import (
+	"math"
+)
+
+// The creep life of the tube follows from the Larson–Miller parameter,
+// LMP = T (C + log10 tr), with T the metal temperature (K) and tr the time to
+// rupture (h). For HP-modified (25Cr-35Ni-Nb) centrifugally cast tubes the
+// minimum rupture strength is fitted by log10 σ = 5.939 - 1.54e-4 LMP, with
+// σ in MPa and C = 20, between 8 and 60 MPa.
+const (
+	larsonMillerC         = 20
+	larsonMillerIntercept = 5.939
+	larsonMillerSlope     = 1.54e-4
+)
+
+// hoopStress returns the mean-diameter hoop stress (MPa) in a tube of inside
+// diameter D and wall thickness t (m) at gauge pressure p (kPa).
+func hoopStress(p, D, t float64) float64 {
+	return p / 1000 * (D + t) / (2 * t)
+}
+
+// ruptureLife returns the time to creep rupture (h) of HP-modified alloy at
+// stress σ (MPa) and metal temperature T (K).
+func ruptureLife(σ, T float64) float64 {
+	lmp := (larsonMillerIntercept - math.Log10(σ)) / larsonMillerSlope
+	return math.Pow(10, lmp/T-larsonMillerC)
+}
+
+// creep fills the wall temperatures, stress and rupture life of p along the
+// tube, from a transposed solver table. The stress uses the pressure above
+// atmospheric.
+func (m *model) creep(p *Profile, wValues []float64, states [][]float64) {
+	y := make([]float64, len(states))
+	for k, w := range wValues {
+		for i := range y {
+			y[i] = states[i][k]
+		}
+		inner, outer := m.wallTemperatures(w, y)
+		σ := hoopStress(y[m.iP()]-101.325, m.Geometry.D, m.Wall.Thickness)
+		p.InnerWall = append(p.InnerWall, inner)
+		p.OuterWall = append(p.OuterWall, outer)
+		p.Stress = append(p.Stress, σ)
+		// the outside of the wall is hottest when it is heated
+		p.RuptureLife = append(p.RuptureLife, ruptureLife(σ, math.Max(inner, outer)))
+	}
+}
@@ -0,0 +1,83 @@
+package reactor
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestRuptureLife(t *testing.T) {
+	// about 11 MPa for 100 000 h at 1000 °C
+	if tr := ruptureLife(10.9, 1273.15); math.Abs(math.Log10(tr)-5) > 0.05 {
+		t.Errorf("expected a rupture life of about 1e5 h; got %e", tr)
+	}
+	if hot, cold := ruptureLife(12, 1200), ruptureLife(12, 1150); hot >= cold {
+		t.Errorf("expected a shorter life when hotter; got %e h at 1200 K and %e h at 1150 K", hot, cold)
+	}
+	if σ := hoopStress(2000, 0.1, 0.01); math.Abs(σ-11) > 1e-12 {
+		t.Errorf("incorrect hoop stress: expected 11 MPa; got %f", σ)
+	}
+}
+
+func TestSimulateCreep(t *testing.T) {
+	c := DefaultConfig()
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := newModel(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := range p.W {
+		if p.InnerWall[k] <= p.T[k] || p.OuterWall[k] <= p.InnerWall[k] || p.OuterWall[k] >= p.Talpha[k] {
+			t.Fatalf("step %d: expected %f < %f < %f < %f K", k, p.T[k], p.InnerWall[k], p.OuterWall[k], p.Talpha[k])
+		}
+		if Δ := p.WallFlux[k] * m.wallResistance(); math.Abs(p.OuterWall[k]-p.InnerWall[k]-Δ) > 1e-9 {
+			t.Fatalf("step %d: expected %f K across the wall; got %f", k, Δ, p.OuterWall[k]-p.InnerWall[k])
+		}
+		// the wall is consistent with the fixed U, the bed taking the rest of 1/U
+		if Δ := p.WallFlux[k] * m.outsideResistance(); math.Abs(p.Talpha[k]-p.OuterWall[k]-Δ) > 1e-9 {
+			t.Fatalf("step %d: expected %f K outside the wall; got %f", k, Δ, p.Talpha[k]-p.OuterWall[k])
+		}
+		bed := 1/c.Heating.U - m.wallResistance() - m.outsideResistance()
+		if Δ := p.WallFlux[k] * bed; math.Abs(p.InnerWall[k]-p.T[k]-Δ) > 1e-6 {
+			t.Fatalf("step %d: expected %f K across the bed; got %f", k, Δ, p.InnerWall[k]-p.T[k])
+		}
+	}
+	tmt, hottest := p.MaxTMT()
+	life, weakest := p.RemainingLife(0)
+	if tmt != p.OuterWall[hottest] || weakest != hottest || life != p.RuptureLife[weakest] {
+		t.Errorf("expected the weakest step to be the hottest; got steps %d and %d", weakest, hottest)
+	}
+	if served, _ := p.RemainingLife(1e4); math.Abs(life-served-1e4) > 1e-6*life {
+		t.Errorf("expected service to consume life; got %e h after %e h", served, life)
+	}
+
+	// under a heat flux U is the bed-side coefficient
+	flux := c
+	flux.Heating.Mode, flux.Heating.Profile = HeatFlux, Table{Z: []float64{0}, Values: []float64{60000}}
+	if p, err = Simulate(context.Background(), flux); err != nil {
+		t.Fatal(err)
+	}
+	if Δ := 60000 / c.Heating.U; math.Abs(p.InnerWall[0]-p.T[0]-Δ) > 1e-6 {
+		t.Errorf("expected %f K across the bed; got %f", Δ, p.InnerWall[0]-p.T[0])
+	}
+	flux.Heating.U = 0
+	if _, err := Simulate(context.Background(), flux); err == nil {
+		t.Error("expected an error for the wall under a heat flux without U")
+	}
+	conductive := c
+	conductive.Heating.U = 60
+	if _, err := Simulate(context.Background(), conductive); err == nil {
+		t.Error("expected an error for a U above the coefficient of the wall and outside")
+	}
+
+	c.Wall.Thickness = 0
+	if p, err = Simulate(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if p.RuptureLife != nil {
+		t.Errorf("expected no creep life without a wall")
+	}
+}
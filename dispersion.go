@@ -0,0 +1,331 @@
+package reactor
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/ode"
+	"github.com/ewancook/reactor/thermo"
+	"github.com/ewancook/reactor/units"
+)
+
+const (
+	// relaxationTolerance bounds the residual of the discretised dispersion
+	// equations, scaled by the feed flow, 1 K and 1 kPa, at which the bed is
+	// taken to be steady.
+	relaxationTolerance = 1e-6
+	maxRelaxations      = 40
+)
+
+// The axial dispersion bed is a boundary-value problem: dispersion carries
+// species and heat upstream, the feed enters through Danckwerts conditions
+// (the convective feed equals the convective and dispersive flux just inside
+// the bed) and nothing disperses through the outlet. The tube is divided into
+// Dispersion.Cells finite volumes, upwinded so that each cell holds the flows,
+// T, P and Tα leaving it, and the discretised equations are integrated in
+// pseudo-time (kg) until steady. Energy is balanced as enthalpy flows, so the
+// heats of reaction and the sensible heat of dispersing species need no
+// separate terms. Counter-current heating falls out without shooting, with
+// Tα upwinded from the outlet.
+
+// edwardsRichardson returns the axial Péclet number for mass, u·dp/Dax, of a
+// packed bed of gas from 1/Pe = 0.73ϕ/(Re·Sc) + 0.5/(1 + 9.7ϕ/(Re·Sc)).
+func edwardsRichardson(Re, Sc, ϕ float64) float64 {
+	ReSc := Re * Sc
+	return 1 / (0.73*ϕ/ReSc + 0.5/(1+9.7*ϕ/ReSc))
+}
+
+// yagiKuniiWakao returns the axial Péclet number for heat, u·ρ·cp·dp/λax, from
+// λax = λ0 + 0.5·dp·G·cp, where the stagnant bed conductivity λ0 is taken as
+// that of the gas in the voids.
+func yagiKuniiWakao(Re, Pr, ϕ float64) float64 {
+	return 1 / (ϕ/(Re*Pr) + 0.5)
+}
+
+// stride returns the length of the state of one cell.
+func (m *model) stride() int {
+	return m.n + 3
+}
+
+// cell returns the state of cell k within y.
+func (m *model) cell(y []float64, k int) []float64 {
+	return y[k*m.stride() : (k+1)*m.stride()]
+}
+
+// axial returns the total flow (mol/s), heat capacity flow (W/K) and axial
+// Péclet numbers for mass and heat of the cell state c.
+func (m *model) axial(c []float64) (flow, capacity, Pem, Peh float64) {
+	for i := 0; i < m.n; i++ {
+		flow += c[i]
+	}
+	capacity = m.mech.heatCapacity(c[m.n], c)
+	massFlow := m.mech.massFlow(c) / 1000
+	Re := massFlow / m.area * m.Catalyst.Dp / m.Gas.Viscosity
+	Pr := capacity / massFlow * m.Gas.Viscosity / m.Gas.Conductivity
+	// the molecular Schmidt number is taken equal to Pr (unit Lewis number)
+	return flow, capacity, edwardsRichardson(Re, Pr, m.Catalyst.Voidage), yagiKuniiWakao(Re, Pr, m.Catalyst.Voidage)
+}
+
+// faces returns the dispersive flow of each species (mol/s) through each face
+// between neighbouring cells, and the heat (W) carried by conduction and by
+// the dispersing species, in the direction of flow.
+func (m *model) faces(y []float64) (J [][]float64, E []float64) {
+	n, N := m.n, m.Dispersion.Cells
+	if m.Bed != AxialDispersion {
+		// a plug flow bed discretised for a transient simulation
+		J, E = make([][]float64, N-1), make([]float64, N-1)
+		for k := range J {
+			J[k] = make([]float64, n)
+		}
+		return J, E
+	}
+	Δz := m.Geometry.Length / float64(N)
+	flows, capacities := make([]float64, N), make([]float64, N)
+	Pem, Peh := make([]float64, N), make([]float64, N)
+	for k := range flows {
+		flows[k], capacities[k], Pem[k], Peh[k] = m.axial(m.cell(y, k))
+	}
+	J, E = make([][]float64, N-1), make([]float64, N-1)
+	for k := range J {
+		a, b := m.cell(y, k), m.cell(y, k+1)
+		// Dax·c·area/Δz, where the molar flux F/area is u·c
+		D := m.Catalyst.Dp * (flows[k]/Pem[k] + flows[k+1]/Pem[k+1]) / 2 / Δz
+		J[k] = make([]float64, n)
+		for i, compound := range m.mech.species {
+			J[k][i] = D * (a[i]/flows[k] - b[i]/flows[k+1])
+			source := a[n]
+			if J[k][i] < 0 {
+				source = b[n]
+			}
+			E[k] += J[k][i] * thermo.Enthalpy(compound, source) * 1000
+		}
+		// λax·area/Δz, where G·cp·area is the heat capacity flow
+		λ := m.Catalyst.Dp * (capacities[k]/Peh[k] + capacities[k+1]/Peh[k+1]) / 2 / Δz
+		E[k] += λ * (a[n] - b[n])
+	}
+	return J, E
+}
+
+// flueFlows returns the heating gas flows of one tube (mol/s), ordered as
+// flueSpecies.
+func (m *model) flueFlows() []float64 {
+	flows := make([]float64, len(flueSpecies))
+	for i, compound := range flueSpecies {
+		flows[i] = m.Flue[compound] / m.Geometry.Tubes
+	}
+	return flows
+}
+
+// dispersionODEs evaluates the pseudo-time derivatives of the discretised
+// dispersion bed, which are zero at steady state.
+func (m *model) dispersionODEs(f la.Vector, h, x float64, y la.Vector) {
+	n, N := m.n, m.Dispersion.Cells
+	ΔW := m.W / float64(N)
+	Δz := m.Geometry.Length / float64(N)
+	J, E := m.faces(y)
+	feed, flue := m.initial(), m.flueFlows()
+	local := make([]float64, m.stride())
+	for k := 0; k < N; k++ {
+		c, r := m.cell(y, k), m.cell(f, k)
+		up := feed
+		if k > 0 {
+			up = m.cell(y, k-1)
+		}
+		w := float64(k+1) * ΔW
+		// local holds the plug flow gradients at the cell state
+		m.ODEs(local, 0, w, c)
+		q := m.flux(w, c) * math.Pi * m.Geometry.D * Δz
+
+		energy := q + (enthalpyFlow(m.mech.species, up[:n], up[n])-enthalpyFlow(m.mech.species, c[:n], c[n]))*1000
+		for i := 0; i < n; i++ {
+			r[i] = up[i] - c[i] + ΔW*local[i]
+		}
+		if k > 0 {
+			for i := range J[k-1] {
+				r[i] += J[k-1][i]
+			}
+			energy += E[k-1]
+		}
+		if k+1 < N {
+			for i := range J[k] {
+				r[i] -= J[k][i]
+			}
+			energy -= E[k]
+		}
+		_, capacity, _, _ := m.axial(c)
+		for i := 0; i < n; i++ {
+			r[i] /= ΔW
+		}
+		r[n] = energy / capacity / ΔW
+		r[n+1] = (up[n+1]-c[n+1])/ΔW + local[n+1]
+
+		upstream := m.Heating.Talpha
+		switch {
+		case m.counterCurrent() && k+1 < N:
+			upstream = m.cell(y, k+1)[n+2]
+		case !m.counterCurrent() && k > 0:
+			upstream = up[n+2]
+		}
+		if m.Mode == Methanation || m.prescribed() {
+			r[n+2] = (m.Heating.Talpha - c[n+2]) / ΔW
+			continue
+		}
+		var flueCapacity float64
+		for i, cp := range flueCP {
+			flueCapacity += cp(c[n+2]) * flue[i]
+		}
+		released := (enthalpyFlow(flueSpecies, flue, upstream)-enthalpyFlow(flueSpecies, flue, c[n+2]))*1000 - q
+		r[n+2] = released / flueCapacity / ΔW
+	}
+}
+
+// banded returns a finite-difference Jacobian of fcn, a function of the cell
+// states in which each cell only sees its neighbours. Every third cell is
+// perturbed at once, so the Jacobian costs 3·stride evaluations, whatever the
+// number of cells.
+func (m *model) banded(fcn ode.Func) ode.JacF {
+	return func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		N, stride := m.Dispersion.Cells, m.stride()
+		if dfdy.Max() == 0 {
+			dfdy.Init(len(y), len(y), N*stride*3*stride)
+		}
+		dfdy.Start()
+		f0, f1 := la.NewVector(len(y)), la.NewVector(len(y))
+		fcn(f0, h, x, y)
+		perturbed := y.GetCopy()
+		for colour := 0; colour < 3; colour++ {
+			for j := 0; j < stride; j++ {
+				copy(perturbed, y)
+				for k := colour; k < N; k += 3 {
+					i := k*stride + j
+					perturbed[i] += 1e-7 * math.Max(math.Abs(y[i]), 1e-5)
+				}
+				fcn(f1, h, x, perturbed)
+				for k := colour; k < N; k += 3 {
+					col := k*stride + j
+					δ := perturbed[col] - y[col]
+					first, last := k-1, k+1
+					if first < 0 {
+						first = 0
+					}
+					if last >= N {
+						last = N - 1
+					}
+					for row := first * stride; row < (last+1)*stride; row++ {
+						dfdy.Put(row, col, (f1[row]-f0[row])/δ)
+					}
+				}
+			}
+		}
+	}
+}
+
+// relax solves the dispersion bed, starting from the plug flow solution, and
+// returns it as solver steps and a transposed table.
+func (m *model) relax(ctx context.Context) ([]float64, [][]float64, error) {
+	y, err := m.guess(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if y, err = m.steady(ctx, y); err != nil {
+		return nil, nil, err
+	}
+	wValues, yValues := m.dispersionTable(y)
+	return wValues, yValues, nil
+}
+
+// steady integrates the cell states y in pseudo-time until they are steady.
+func (m *model) steady(ctx context.Context, y []float64) ([]float64, error) {
+	f := make(la.Vector, len(y))
+	for i := 0; i < maxRelaxations; i++ {
+		_, yValues, err := m.solve(ctx, m.dispersionODEs, m.banded(m.dispersionODEs), y, 0, m.W, nil)
+		if err != nil {
+			return nil, err
+		}
+		for j := range y {
+			y[j] = yValues[j][len(yValues[j])-1]
+		}
+		m.dispersionODEs(f, 0, 0, y)
+		if m.residual(f) < relaxationTolerance {
+			return y, nil
+		}
+	}
+	return nil, fmt.Errorf("reactor: dispersion bed not steady after %d relaxations", maxRelaxations)
+}
+
+// guess returns the plug flow solution at the cell outlets, which is close
+// to steady whenever dispersion is weak.
+func (m *model) guess(ctx context.Context) ([]float64, error) {
+	var wValues []float64
+	var yValues [][]float64
+	var err error
+	if m.counterCurrent() {
+		wValues, yValues, _, err = m.shoot(ctx)
+	} else {
+		wValues, yValues, err = m.integrate(ctx, m.initial())
+	}
+	if err != nil {
+		return nil, err
+	}
+	N := m.Dispersion.Cells
+	y := make([]float64, N*m.stride())
+	for k := 0; k < N; k++ {
+		w := float64(k+1) * m.W / float64(N)
+		for i, series := range yValues {
+			y[k*m.stride()+i] = Table{Z: wValues, Values: series}.At(w)
+		}
+	}
+	return y, nil
+}
+
+// residual returns the largest scaled residual of the discretised equations,
+// given their pseudo-time derivatives f.
+func (m *model) residual(f []float64) float64 {
+	ΔW := m.W / float64(m.Dispersion.Cells)
+	var norm float64
+	for j, v := range f {
+		scale := 1.0
+		if j%m.stride() < m.n {
+			scale = m.F0.In(units.MolPerS)
+		}
+		norm = math.Max(norm, math.Abs(v)*ΔW/scale)
+	}
+	return norm
+}
+
+// dispersionTable returns the state of the dispersion bed y at the inlet and
+// at each cell outlet as solver steps and a transposed table. The flows are
+// the total of convection and dispersion through each face.
+func (m *model) dispersionTable(y []float64) ([]float64, [][]float64) {
+	n, N := m.n, m.Dispersion.Cells
+	J, _ := m.faces(y)
+	wValues := make([]float64, N+1)
+	yValues := make([][]float64, m.stride())
+	for i := range yValues {
+		yValues[i] = make([]float64, N+1)
+		yValues[i][0] = m.initial()[i]
+	}
+	for k := 0; k < N; k++ {
+		wValues[k+1] = float64(k+1) * m.W / float64(N)
+		c := m.cell(y, k)
+		for i := range c {
+			yValues[i][k+1] = c[i]
+		}
+		if k+1 < N {
+			for i := 0; i < n; i++ {
+				yValues[i][k+1] += J[k][i]
+			}
+		}
+	}
+	// each cell holds the heating gas leaving it, which is at its inlet face
+	// in counter-current flow
+	if m.counterCurrent() {
+		for k := 0; k < N; k++ {
+			yValues[n+2][k] = m.cell(y, k)[n+2]
+		}
+		yValues[n+2][N] = m.Heating.Talpha
+	}
+	return wValues, yValues
+}
@@ -0,0 +1,384 @@
+package reactor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Event is a condition on the state of the tube, which is met where Func is
+// not negative. It occurs at the inlet if met there, and wherever Func changes
+// sign along the tube. Simulate finds each occurrence between two solver
+// steps by integrating again from the first of them. An Event that Stops ends
+// the tube at its first occurrence, which needs an initial-value problem: it
+// cannot be used with counter-current heating or the AxialDispersion bed,
+// where the whole tube is solved at once.
+type Event struct {
+	Name    string // names occurrences where the condition becomes met
+	Falling string // names those where it stops being met, or Name if empty
+	Func    func(s State) float64
+	Stop    bool
+}
+
+// Occurrence records where an event occurred.
+type Occurrence struct {
+	Name   string  // the Name or Falling name of the event
+	W, Z   float64 // catalyst mass (kg) and axial position (m) from the inlet
+	Rising bool    // whether Func rose through zero, or was met at the inlet
+	Stop   bool    // whether the tube ends here
+}
+
+// occurrence returns the Occurrence of e at catalyst mass w and axial
+// position z.
+func (e Event) occurrence(w, z float64, rising bool) Occurrence {
+	name := e.Name
+	if !rising && e.Falling != "" {
+		name = e.Falling
+	}
+	return Occurrence{Name: name, W: w, Z: z, Rising: rising}
+}
+
+// ConversionAbove occurs where the conversion of the key reactant rises above
+// x.
+func ConversionAbove(x float64) Event {
+	return Event{
+		Name:    fmt.Sprintf("conversion above %g", x),
+		Falling: fmt.Sprintf("conversion below %g", x),
+		Func:    func(s State) float64 { return s.Conversion() - x },
+	}
+}
+
+// TemperatureAbove occurs where the process gas rises above T (K).
+func TemperatureAbove(T float64) Event {
+	return Event{
+		Name:    fmt.Sprintf("temperature above %g K", T),
+		Falling: fmt.Sprintf("temperature below %g K", T),
+		Func:    func(s State) float64 { return s.T() - T },
+	}
+}
+
+// WallTemperatureAbove occurs where the tube wall rises above T (K).
+func WallTemperatureAbove(T float64) Event {
+	return Event{
+		Name:    fmt.Sprintf("wall temperature above %g K", T),
+		Falling: fmt.Sprintf("wall temperature below %g K", T),
+		Func:    func(s State) float64 { return s.WallTemperature() - T },
+	}
+}
+
+// PressureBelow occurs where the pressure falls below P (kPa).
+func PressureBelow(P float64) Event {
+	return Event{
+		Name:    fmt.Sprintf("pressure below %g kPa", P),
+		Falling: fmt.Sprintf("pressure above %g kPa", P),
+		Func:    func(s State) float64 { return P - s.P() },
+	}
+}
+
+// CarbonFormation occurs where the process gas becomes able to deposit
+// carbon, that is where its CarbonActivity rises above one, and falls as
+// "carbon gasification" where the activity falls below one again.
+func CarbonFormation() Event {
+	return Event{
+		Name:    "carbon formation",
+		Falling: "carbon gasification",
+		Func:    func(s State) float64 { return math.Log(s.CarbonActivity()) },
+	}
+}
+
+// State is the state of one tube at a point along it, as seen by event
+// functions. It shares the model of the simulation, so it must not be kept
+// beyond the call.
+type State struct {
+	W, Z float64 // catalyst mass (kg) and axial position (m) from the inlet
+	m    *model
+	y    []float64
+}
+
+// state returns the State of m at catalyst mass w for the solver state y.
+func (m *model) state(w float64, y []float64) State {
+	if m.zones != nil {
+		m = m.zone(w)
+	}
+	return State{W: w, Z: m.z(w), m: m, y: y}
+}
+
+// Flow returns the flow of compound (mol/s).
+func (s State) Flow(compound string) float64 {
+	i := s.m.mech.index(compound)
+	if i < 0 {
+		return 0
+	}
+	var flow float64
+	for j := 0; j < s.m.rings; j++ {
+		flow += s.y[j*(s.m.n+1)+i]
+	}
+	return flow
+}
+
+// T returns the process gas (cup-mixing) temperature (K).
+func (s State) T() float64 {
+	if s.m.rings == 1 {
+		return s.y[s.m.n]
+	}
+	table := make([][]float64, len(s.y))
+	for i := range table {
+		table[i] = s.y[i : i+1]
+	}
+	return s.m.mixed(table)[s.m.n][0]
+}
+
+// P returns the pressure (kPa).
+func (s State) P() float64 {
+	return s.y[s.m.iP()]
+}
+
+// Talpha returns the heating gas, coolant or prescribed wall temperature (K),
+// as in Profile.
+func (s State) Talpha() float64 {
+	if s.m.Heating.Mode == WallTemperature {
+		return s.m.Heating.Profile.At(s.Z)
+	}
+	return s.y[s.m.iTα()]
+}
+
+// Conversion returns the conversion of the key reactant since the inlet.
+func (s State) Conversion() float64 {
+	key := s.m.mech.key
+	return 1 - s.Flow(key)/(s.m.Feed[key]/s.m.Geometry.Tubes)
+}
+
+// WallTemperature returns the temperature of the hotter side of the tube
+// wall (K).
+func (s State) WallTemperature() float64 {
+	inner, outer := s.m.wallTemperatures(s.W, s.y)
+	return math.Max(inner, outer)
+}
+
+// CarbonActivity returns the activity of carbon in the process gas by the
+// principle of actual gas affinity: the larger of the activities found from
+// methane cracking, CH4 = C + 2H2, and the Boudouard reaction, 2CO = C + CO2,
+// as if each were at equilibrium. Carbon can form where it exceeds one. It is
+// zero for mechanisms without carbon.
+func (s State) CarbonActivity() float64 {
+	mech := s.m.mech
+	iCH4, iH2, iCO, iCO2 := mech.index("CH4"), mech.index("H2"), mech.index("CO"), mech.index("CO2")
+	if iCH4 < 0 || iCO < 0 {
+		return 0
+	}
+	flow := make([]float64, s.m.n+2)
+	for i := 0; i < s.m.n; i++ {
+		flow[i] = s.Flow(mech.species[i])
+	}
+	flow[s.m.n], flow[s.m.n+1] = s.T(), s.P()
+	p := make([]float64, s.m.n)
+	mech.partials(flow, p)
+	T := flow[s.m.n]
+	cracking := kCracking(T) * p[iCH4] / math.Pow(hydrogen(p[iH2]), 2)
+	boudouard := kBoudouard(T) * p[iCO] * p[iCO] / bounded(p[iCO2])
+	return math.Max(cracking, boudouard)
+}
+
+// met reports whether an event function g is on the side where its
+// condition is met, as for a rising crossing.
+func met(g float64) bool {
+	return g >= 0
+}
+
+// crossed reports whether an event function has changed sign from a to b.
+func crossed(a, b float64) bool {
+	return (a < 0 && b >= 0) || (a > 0 && b <= 0)
+}
+
+// stopper watches the events that stop the tube at every solver step.
+type stopper struct {
+	m       *model
+	events  []Event
+	last    []float64
+	started bool
+	fired   bool
+}
+
+// stopper returns a stopper for the events of m that stop the tube, or nil if
+// there are none.
+func (m *model) stopper() *stopper {
+	s := &stopper{m: m}
+	for _, e := range m.Events {
+		if e.Stop {
+			s.events = append(s.events, e)
+		}
+	}
+	if s.events == nil {
+		return nil
+	}
+	s.last = make([]float64, len(s.events))
+	return s
+}
+
+// step reports whether an event has stopped the tube by catalyst mass w,
+// where the solver state is y: either at the inlet, where its condition is
+// met, or after it has crossed.
+func (s *stopper) step(w float64, y []float64) bool {
+	state := s.m.state(w, y)
+	for i, e := range s.events {
+		g := e.Func(state)
+		if (s.started && crossed(s.last[i], g)) || (!s.started && met(g)) {
+			s.fired = true
+		}
+		s.last[i] = g
+	}
+	s.started = true
+	return s.fired
+}
+
+// events finds the occurrences of the events of m at the inlet and between
+// the steps of a transposed solver table, in order along the tube, and ends
+// the table at the first occurrence of an event that stops the tube.
+func (m *model) events(ctx context.Context, wValues []float64, yValues [][]float64) ([]float64, [][]float64, []Occurrence, error) {
+	if len(m.Events) == 0 {
+		return wValues, yValues, nil, nil
+	}
+	column := func(k int) []float64 {
+		y := make([]float64, len(yValues))
+		for i := range y {
+			y[i] = yValues[i][k]
+		}
+		return y
+	}
+	last := make([]float64, len(m.Events))
+	y := column(0)
+	inlet := m.state(wValues[0], y)
+	var occurrences []Occurrence
+	stopped := false
+	for i, e := range m.Events {
+		last[i] = e.Func(inlet)
+		if met(last[i]) {
+			o := e.occurrence(inlet.W, inlet.Z, true)
+			o.Stop = e.Stop && !stopped
+			stopped = stopped || o.Stop
+			occurrences = append(occurrences, o)
+		}
+	}
+	if stopped {
+		// the tube ends at its inlet
+		for i := range yValues {
+			yValues[i] = yValues[i][:1:1]
+		}
+		return wValues[:1:1], yValues, occurrences, nil
+	}
+	for k := 1; k < len(wValues); k++ {
+		y0, y1 := y, column(k)
+		first := len(occurrences)
+		stop := -1
+		var wStop float64
+		var yStop []float64
+		for i, e := range m.Events {
+			g := e.Func(m.state(wValues[k], y1))
+			if crossed(last[i], g) {
+				w, yw, err := m.locate(ctx, e, wValues[k-1], y0, last[i], wValues[k], y1, g)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				occurrences = append(occurrences, e.occurrence(w, m.state(w, yw).Z, g > last[i]))
+				if e.Stop && (stop < 0 || w < wStop) {
+					stop, wStop, yStop = len(occurrences)-1, w, yw
+				}
+			}
+			last[i] = g
+		}
+		y = y1
+		if stop >= 0 {
+			occurrences[stop].Stop = true
+		}
+		step := occurrences[first:]
+		sort.SliceStable(step, func(a, b int) bool { return step[a].W < step[b].W })
+		if stop < 0 {
+			continue
+		}
+		// the tube ends at the first stop, where the steps after it and any
+		// later occurrences are dropped
+		kept := occurrences[:0]
+		for _, o := range occurrences {
+			if o.W <= wStop {
+				kept = append(kept, o)
+			}
+		}
+		wValues = append(wValues[:k:k], wStop)
+		for i := range yValues {
+			yValues[i] = append(yValues[i][:k:k], yStop[i])
+		}
+		return wValues, yValues, kept, nil
+	}
+	return wValues, yValues, occurrences, nil
+}
+
+// locate finds where the function of event e changes sign between catalyst
+// masses w0 and w1, with solver states y0 and y1 and function values g0 and
+// g1, by the Illinois method. Each trial state is found by integrating from
+// w0, or by interpolation for the AxialDispersion bed, whose table holds the
+// cell outlets.
+func (m *model) locate(ctx context.Context, e Event, w0 float64, y0 []float64, g0, w1 float64, y1 []float64, g1 float64) (float64, []float64, error) {
+	if w1 == w0 {
+		// the repeated step at the start of a zone
+		return w1, y1, nil
+	}
+	at := func(w float64) ([]float64, error) {
+		if m.Bed == AxialDispersion {
+			y := make([]float64, len(y0))
+			for i := range y {
+				y[i] = y0[i] + (y1[i]-y0[i])*(w-w0)/(w1-w0)
+			}
+			return y, nil
+		}
+		zone := m
+		if m.zones != nil {
+			zone = m.zone(w0)
+		}
+		_, yValues, err := m.solve(ctx, zone.ODEs, zone.jacobian(), y0, w0, w, nil)
+		if err != nil {
+			return nil, err
+		}
+		y := make([]float64, len(yValues))
+		for i := range y {
+			y[i] = yValues[i][len(yValues[i])-1]
+		}
+		return y, nil
+	}
+
+	a, ga, b, gb, yb := w0, g0, w1, g1, y1
+	side := 0
+	for i := 0; i < maxEventIterations && math.Abs(b-a) > eventTolerance*m.W; i++ {
+		w := b - gb*(b-a)/(gb-ga)
+		y, err := at(w)
+		if err != nil {
+			return 0, nil, err
+		}
+		g := e.Func(m.state(w, y))
+		switch {
+		case g == 0:
+			return w, y, nil
+		case crossed(ga, g):
+			b, gb, yb = w, g, y
+			if side == -1 {
+				ga /= 2
+			}
+			side = -1
+		default:
+			a, ga = w, g
+			if side == 1 {
+				gb /= 2
+			}
+			side = 1
+		}
+	}
+	return b, yb, nil
+}
+
+const (
+	// eventTolerance is the accepted uncertainty in the catalyst mass at
+	// which an event occurs, relative to the mass of the tube, and
+	// maxEventIterations bounds the iterations to find it.
+	eventTolerance     = 1e-9
+	maxEventIterations = 60
+)
@@ -0,0 +1,168 @@
+package reactor
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestStopEvent(t *testing.T) {
+	zoned := DefaultConfig()
+	zoned.Zones = []Zone{{Length: 5}, {Length: 10, D: 0.1}}
+	radial := DefaultConfig()
+	radial.Bed = Radial2D
+	for name, c := range map[string]Config{"plug flow": DefaultConfig(), "zones": zoned, "radial": radial} {
+		c.Events = []Event{ConversionAbove(0.4)}
+		c.Events[0].Stop = true
+		p, err := Simulate(context.Background(), c)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		last := len(p.W) - 1
+		if conversion := p.Conversion()[last]; math.Abs(conversion-0.4) > 1e-6 {
+			t.Errorf("%s: expected the tube to end at a conversion of 0.4; got %f", name, conversion)
+		}
+		if len(p.Events) != 1 || !p.Events[0].Stop || !p.Events[0].Rising {
+			t.Fatalf("%s: expected one rising stop; got %+v", name, p.Events)
+		}
+		if o := p.Events[0]; o.W != p.W[last] || o.Z != p.Z[last] || o.Z >= c.Geometry.Length {
+			t.Errorf("%s: expected the tube to end at the event at %f m; got %f m", name, o.Z, p.Z[last])
+		}
+	}
+}
+
+func TestEventLocation(t *testing.T) {
+	c := DefaultConfig()
+	c.Events = []Event{TemperatureAbove(1000), PressureBelow(2300), CarbonFormation()}
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Events = nil
+	plain, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.W) != len(plain.W) || p.T[len(p.T)-1] != plain.T[len(plain.T)-1] {
+		t.Errorf("expected events that do not stop to leave the profile alone")
+	}
+	// the methane-rich feed can already deposit carbon at the inlet
+	if len(p.Events) != 3 || p.Events[0].Name != "carbon formation" || p.Events[0].W != 0 || !p.Events[0].Rising ||
+		p.Events[1].Name != "temperature above 1000 K" || p.Events[2].Name != "pressure below 2300 kPa" {
+		t.Fatalf("expected carbon formation at the inlet, then the temperature and pressure events; got %+v", p.Events)
+	}
+
+	// integrate to each occurrence and check the condition holds there
+	m, err := newModel(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range p.Events[1:] {
+		_, yValues, err := m.solve(context.Background(), m.ODEs, m.jacobian(), m.initial(), 0, o.W, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y := make([]float64, len(yValues))
+		for i := range y {
+			y[i] = yValues[i][len(yValues[i])-1]
+		}
+		s := m.state(o.W, y)
+		if math.Abs(s.T()-1000) > 1e-3 && math.Abs(s.P()-2300) > 1e-3 {
+			t.Errorf("%s at %f kg: T is %f K and P %f kPa", o.Name, o.W, s.T(), s.P())
+		}
+	}
+}
+
+func TestEventOrder(t *testing.T) {
+	c := DefaultConfig()
+	// the feed enters above 800 K and cools below it before heating up
+	c.Events = []Event{ConversionAbove(0.5), ConversionAbove(0.2), TemperatureAbove(800)}
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for k, o := range p.Events {
+		names = append(names, o.Name)
+		if k > 0 && o.W < p.Events[k-1].W {
+			t.Errorf("expected the events in order along the tube; got %+v", p.Events)
+		}
+	}
+	expected := []string{"temperature above 800 K", "temperature below 800 K", "temperature above 800 K", "conversion above 0.2", "conversion above 0.5"}
+	if strings.Join(names, "; ") != strings.Join(expected, "; ") {
+		t.Errorf("expected %v; got %v", expected, names)
+	}
+
+	// events crossed within one step are sorted too
+	m, err := newModel(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := len(p.W) - 1
+	y0 := m.initial()
+	yValues := make([][]float64, len(y0))
+	for i := range y0 {
+		yValues[i] = []float64{y0[i], 0}
+	}
+	for i, compound := range p.Species {
+		yValues[i][1] = p.Flows[compound][last]
+	}
+	yValues[m.n][1], yValues[m.iP()][1], yValues[m.iTα()][1] = p.T[last], p.P[last], p.Talpha[last]
+	_, _, occurrences, err := m.events(context.Background(), []float64{0, m.W}, yValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(occurrences) != 3 || occurrences[1].Name != "conversion above 0.2" || occurrences[2].Name != "conversion above 0.5" {
+		t.Errorf("expected the conversion events in order within the step; got %+v", occurrences)
+	}
+
+	// a stop met at the inlet ends the tube there
+	c.Events = []Event{CarbonFormation(), ConversionAbove(0.1)}
+	c.Events[0].Stop = true
+	if p, err = Simulate(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.W) != 1 || len(p.Events) != 1 || !p.Events[0].Stop || p.Events[0].W != 0 {
+		t.Errorf("expected the tube to stop at its inlet; got %d steps and %+v", len(p.W), p.Events)
+	}
+}
+
+func TestCarbonActivity(t *testing.T) {
+	m, err := newModel(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the methane-rich feed at the inlet can crack
+	if a := m.state(0, m.initial()).CarbonActivity(); a <= 1 {
+		t.Errorf("expected carbon to be able to form at the inlet; got an activity of %f", a)
+	}
+	c := DefaultConfig()
+	c.Mode, c.Feed = Cracking, map[string]float64{"NH3": 100}
+	if m, err = newModel(c); err != nil {
+		t.Fatal(err)
+	}
+	if a := m.state(0, m.initial()).CarbonActivity(); a != 0 {
+		t.Errorf("expected no carbon activity in ammonia cracking; got %f", a)
+	}
+}
+
+func TestInvalidEvents(t *testing.T) {
+	stop := ConversionAbove(0.5)
+	stop.Stop = true
+	counter := DefaultConfig()
+	counter.Heating.Arrangement = CounterCurrent
+	dispersion := DefaultConfig()
+	dispersion.Bed = AxialDispersion
+	for name, c := range map[string]Config{"counter-current": counter, "dispersion": dispersion} {
+		c.Events = []Event{stop}
+		if _, err := Simulate(context.Background(), c); err == nil {
+			t.Errorf("%s: expected an error for a stop event", name)
+		}
+	}
+	c := DefaultConfig()
+	c.Events = []Event{{Name: "nothing"}}
+	if _, err := Simulate(context.Background(), c); err == nil {
+		t.Error("expected an error for an event without a function")
+	}
+}
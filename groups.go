@@ -0,0 +1,200 @@
+package reactor
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// splitTolerance is the largest relative difference between the pressure
+	// drops of the tube groups at which their flow split is accepted.
+	splitTolerance = 1e-5
+	maxSplits      = 30
+)
+
+// TubeGroup is a set of tubes that differ from the rest of the furnace, such
+// as a row next to the refractory wall. Zero fields of Catalyst keep those of
+// the Config, and a group that sets only the voidage keeps the pellet density.
+type TubeGroup struct {
+	Name       string
+	Tubes      float64
+	FluxFactor float64 // multiplies Heating.FluxFactor for the tubes; zero leaves it unchanged
+	Catalyst   Catalyst
+}
+
+// Split holds the solution of a furnace of tube groups fed from common inlet
+// and outlet headers.
+type Split struct {
+	Groups []TubeGroup
+	// Shares holds the feed per tube of each group relative to the mean, so
+	// that the shares weighted by tubes sum to the total number of tubes.
+	Shares     []float64
+	Profiles   []*Profile
+	Iterations int
+}
+
+// ReadGroups reads tube groups from CSV with a header row naming the columns
+// name, tubes, flux, density, voidage and Dp, of which only tubes is required.
+func ReadGroups(r io.Reader) ([]TubeGroup, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("reactor: tube groups need a header and at least one row")
+	}
+	var groups []TubeGroup
+	for i, record := range records[1:] {
+		g := TubeGroup{Name: strconv.Itoa(i + 1)}
+		for j, field := range record {
+			field = strings.TrimSpace(field)
+			column := strings.ToLower(strings.TrimSpace(records[0][j]))
+			if column == "name" {
+				g.Name = field
+				continue
+			}
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("reactor: row %d of tube groups: %v", i+2, err)
+			}
+			switch column {
+			case "tubes":
+				g.Tubes = v
+			case "flux":
+				g.FluxFactor = v
+			case "density":
+				g.Catalyst.Density = v
+			case "voidage":
+				g.Catalyst.Voidage = v
+			case "dp":
+				g.Catalyst.Dp = v
+			default:
+				return nil, fmt.Errorf("reactor: unknown tube group column %q", column)
+			}
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// config returns the configuration of group g given its share of the feed.
+// The flue gas is shared by tubes.
+func (g TubeGroup) config(c Config, share float64) Config {
+	fraction := g.Tubes / c.Geometry.Tubes
+	feed, flue := map[string]float64{}, map[string]float64{}
+	for compound, flow := range c.Feed {
+		feed[compound] = flow * fraction * share
+	}
+	for compound, flow := range c.Flue {
+		flue[compound] = flow * fraction
+	}
+	c.Feed, c.Flue = feed, flue
+	c.Geometry.Tubes = g.Tubes
+	if g.FluxFactor != 0 {
+		if c.Heating.FluxFactor == 0 {
+			c.Heating.FluxFactor = 1
+		}
+		c.Heating.FluxFactor *= g.FluxFactor
+	}
+	if g.Catalyst.Voidage != 0 {
+		c.Catalyst.Density *= (1 - g.Catalyst.Voidage) / (1 - c.Catalyst.Voidage)
+		c.Catalyst.Voidage = g.Catalyst.Voidage
+	}
+	if g.Catalyst.Density != 0 {
+		c.Catalyst.Density = g.Catalyst.Density
+	}
+	if g.Catalyst.Dp != 0 {
+		c.Catalyst.Dp = g.Catalyst.Dp
+	}
+	if g.Catalyst.HeatCapacity != 0 {
+		c.Catalyst.HeatCapacity = g.Catalyst.HeatCapacity
+	}
+	return c
+}
+
+// SimulateGroups simulates each group of tubes of the furnace described by c,
+// whose tubes the groups must share between them. The groups are fed from a
+// common inlet header at c.P and discharge into a common outlet header, so
+// the feed is split between them until their pressure drops agree. Every
+// tube must reach the outlet header, so events may not stop the tubes.
+func SimulateGroups(ctx context.Context, c Config, groups []TubeGroup) (*Split, error) {
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("reactor: no tube groups")
+	}
+	for _, e := range c.Events {
+		if e.Stop {
+			return nil, fmt.Errorf("reactor: event %q cannot stop the tubes of groups that share an outlet header", e.Name)
+		}
+	}
+	var tubes float64
+	for _, g := range groups {
+		if g.Tubes <= 0 {
+			return nil, fmt.Errorf("reactor: tube group %s needs a positive number of tubes", g.Name)
+		}
+		tubes += g.Tubes
+	}
+	if math.Abs(tubes-c.Geometry.Tubes) > 1e-9*c.Geometry.Tubes {
+		return nil, fmt.Errorf("reactor: tube groups hold %g tubes, not %g", tubes, c.Geometry.Tubes)
+	}
+
+	s := &Split{Groups: groups, Shares: make([]float64, len(groups)), Profiles: make([]*Profile, len(groups))}
+	for i := range s.Shares {
+		s.Shares[i] = 1
+	}
+	drops := make([]float64, len(groups))
+	for s.Iterations = 1; ; s.Iterations++ {
+		var mean float64
+		for i, g := range groups {
+			p, err := Simulate(ctx, g.config(c, s.Shares[i]))
+			if err != nil {
+				return nil, fmt.Errorf("reactor: tube group %s: %v", g.Name, err)
+			}
+			s.Profiles[i] = p
+			drops[i] = c.P - p.P[len(p.P)-1]
+			mean += drops[i] * g.Tubes / tubes
+		}
+		converged := true
+		for i := range drops {
+			if math.Abs(drops[i]-mean) > splitTolerance*mean {
+				converged = false
+			}
+		}
+		if converged {
+			return s, nil
+		}
+		if s.Iterations == maxSplits {
+			return nil, fmt.Errorf("reactor: tube group flow split did not converge in %d iterations", maxSplits)
+		}
+		// the pressure drop of a packed bed rises with nearly the square of
+		// the flow
+		var total float64
+		for i, g := range groups {
+			s.Shares[i] *= math.Pow(mean/drops[i], 1/1.8)
+			total += s.Shares[i] * g.Tubes
+		}
+		for i := range s.Shares {
+			s.Shares[i] *= tubes / total
+		}
+	}
+}
+
+// Spread returns the differences between the hottest and coldest outlet
+// temperatures (K) and maximum tube metal temperatures (K) of the groups.
+func (s *Split) Spread() (outletT, tmt float64) {
+	minT, maxT := math.Inf(1), math.Inf(-1)
+	minTMT, maxTMT := math.Inf(1), math.Inf(-1)
+	for _, p := range s.Profiles {
+		T := p.T[len(p.T)-1]
+		minT, maxT = math.Min(minT, T), math.Max(maxT, T)
+		hottest, _ := p.MaxTMT()
+		minTMT, maxTMT = math.Min(minTMT, hottest), math.Max(maxTMT, hottest)
+	}
+	return maxT - minT, maxTMT - minTMT
+}
@@ -0,0 +1,105 @@
+package reactor
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadGroups(t *testing.T) {
+	groups, err := ReadGroups(strings.NewReader("name, tubes, flux, Dp\nwall, 60, 1.2, 0.015\ncentre, 140, 0.9, 0.013\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[1] != (TubeGroup{Name: "centre", Tubes: 140, FluxFactor: 0.9, Catalyst: Catalyst{Dp: 0.013}}) {
+		t.Errorf("incorrect groups: %+v", groups)
+	}
+	for _, bad := range []string{"tubes\n", "tubes, colour\n1, 2\n", "tubes\nmany\n"} {
+		if _, err := ReadGroups(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestTubeGroupConfig(t *testing.T) {
+	c := DefaultConfig()
+	c.Heating.FluxFactor = 1.1
+	g := TubeGroup{Tubes: 50, FluxFactor: 1.2, Catalyst: Catalyst{Voidage: 0.5, Dp: 0.02}}
+	res := g.config(c, 1)
+	if math.Abs(res.Heating.FluxFactor-1.32) > 1e-12 {
+		t.Errorf("expected the flux factors to multiply to 1.32; got %f", res.Heating.FluxFactor)
+	}
+	pellet := c.Catalyst.Density / (1 - c.Catalyst.Voidage)
+	if math.Abs(res.Catalyst.Density/(1-res.Catalyst.Voidage)-pellet) > 1e-9 || res.Catalyst.Dp != 0.02 {
+		t.Errorf("expected the pellet density %f and Dp 0.02; got %+v", pellet, res.Catalyst)
+	}
+	if res.Catalyst.HeatCapacity != c.Catalyst.HeatCapacity {
+		t.Errorf("expected the heat capacity to be kept; got %f", res.Catalyst.HeatCapacity)
+	}
+
+	g = TubeGroup{Tubes: 50, Catalyst: Catalyst{Density: 900, Voidage: 0.5}}
+	if res = g.config(c, 1); res.Heating.FluxFactor != 1.1 || res.Catalyst.Density != 900 {
+		t.Errorf("expected the flux factor 1.1 and density 900; got %f and %f", res.Heating.FluxFactor, res.Catalyst.Density)
+	}
+	c.Heating.FluxFactor = 0
+	g = TubeGroup{Tubes: 50, FluxFactor: 1.2}
+	if res = g.config(c, 1); res.Heating.FluxFactor != 1.2 {
+		t.Errorf("expected the flux factor 1.2; got %f", res.Heating.FluxFactor)
+	}
+}
+
+func TestSimulateGroups(t *testing.T) {
+	c := DefaultConfig()
+	groups := []TubeGroup{
+		{Name: "wall", Tubes: 60, FluxFactor: 1.2},
+		{Name: "centre", Tubes: 140, FluxFactor: 0.9, Catalyst: Catalyst{Voidage: 0.42}},
+	}
+	s, err := SimulateGroups(context.Background(), c, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tubes float64
+	drops := make([]float64, len(groups))
+	for i, p := range s.Profiles {
+		tubes += s.Shares[i] * groups[i].Tubes
+		drops[i] = c.P - p.P[len(p.P)-1]
+	}
+	if math.Abs(tubes-c.Geometry.Tubes) > 1e-9 {
+		t.Errorf("expected the shares to cover %g tubes; got %g", c.Geometry.Tubes, tubes)
+	}
+	if math.Abs(drops[0]-drops[1]) > 1e-4*drops[0] {
+		t.Errorf("expected equal pressure drops; got %f and %f kPa", drops[0], drops[1])
+	}
+	// the denser packing of the centre tubes diverts flow to the wall
+	if s.Shares[0] <= 1 || s.Shares[1] >= 1 {
+		t.Errorf("expected more flow through the wall tubes; got shares %v", s.Shares)
+	}
+	if outletT, tmt := s.Spread(); outletT <= 0 || tmt <= 0 {
+		t.Errorf("expected a spread of temperatures; got %f and %f K", outletT, tmt)
+	}
+
+	// identical groups recover the single tube
+	single, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = SimulateGroups(context.Background(), c, []TubeGroup{{Tubes: 50}, {Tubes: 150}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, res := single.T[len(single.T)-1], s.Profiles[1].T[len(s.Profiles[1].T)-1]
+	if s.Iterations != 1 || math.Abs(res-expected) > 1e-6 {
+		t.Errorf("expected %f K in one iteration; got %f K in %d", expected, res, s.Iterations)
+	}
+
+	if _, err := SimulateGroups(context.Background(), c, []TubeGroup{{Tubes: 100}}); err == nil {
+		t.Errorf("expected an error for groups missing tubes")
+	}
+	stop := ConversionAbove(0.5)
+	stop.Stop = true
+	c.Events = []Event{stop}
+	if _, err := SimulateGroups(context.Background(), c, []TubeGroup{{Tubes: 50}, {Tubes: 150}}); err == nil {
+		t.Errorf("expected an error for an event that stops the tubes")
+	}
+}
@@ -0,0 +1,127 @@
+package reactor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HeatingMode selects how heat reaches the tube wall.
+type HeatingMode string
+
+const (
+	// HeatingGas is an energy balance on the flue gas (or, for Methanation,
+	// a coolant held at Heating.Talpha).
+	HeatingGas HeatingMode = "gas"
+	// WallTemperature prescribes the inside tube wall temperature (K) along
+	// the tube, e.g. from tube-skin thermocouples, with U as the wall-to-gas
+	// coefficient.
+	WallTemperature HeatingMode = "wall-temperature"
+	// HeatFlux prescribes the heat flux into the process gas (W/m^2) along
+	// the tube, e.g. from a furnace vendor's flux curve.
+	HeatFlux HeatingMode = "heat-flux"
+	// Radiation is an energy balance on the flue gas, which radiates to the
+	// tube wall as a well-stirred zone at each position along the tube. U is
+	// then the wall-to-gas coefficient.
+	Radiation HeatingMode = "radiation"
+)
+
+// Table is a profile tabulated against axial position from the process inlet,
+// interpolated linearly and held constant beyond its ends.
+type Table struct {
+	Z      []float64 // axial position (m), increasing
+	Values []float64
+}
+
+// ReadTable reads a Table from CSV rows of axial position (m) and value. A
+// leading row that does not parse as numbers is taken to be a header.
+func ReadTable(r io.Reader) (Table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Table{}, err
+	}
+	var t Table
+	for i, record := range records {
+		z, errZ := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		v, errV := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if errZ != nil || errV != nil {
+			if i == 0 {
+				continue
+			}
+			return Table{}, fmt.Errorf("reactor: row %d of table is not numeric: %q", i+1, record)
+		}
+		t.Z = append(t.Z, z)
+		t.Values = append(t.Values, v)
+	}
+	return t, t.validate()
+}
+
+func (t Table) validate() error {
+	if len(t.Z) == 0 || len(t.Z) != len(t.Values) {
+		return fmt.Errorf("reactor: table needs matching, non-empty positions and values")
+	}
+	for i := 1; i < len(t.Z); i++ {
+		if t.Z[i] <= t.Z[i-1] {
+			return fmt.Errorf("reactor: table positions must increase, but %g follows %g", t.Z[i], t.Z[i-1])
+		}
+	}
+	return nil
+}
+
+// At returns the value interpolated at axial position z (m).
+func (t Table) At(z float64) float64 {
+	if len(t.Z) == 1 || z <= t.Z[0] {
+		return t.Values[0]
+	}
+	if last := len(t.Z) - 1; z >= t.Z[last] {
+		return t.Values[last]
+	}
+	i := sort.SearchFloat64s(t.Z, z) - 1
+	return t.Values[i] + (z-t.Z[i])*(t.Values[i+1]-t.Values[i])/(t.Z[i+1]-t.Z[i])
+}
+
+// z returns the axial position (m) reached after catalyst mass w (kg).
+func (m *model) z(w float64) float64 {
+	return m.zStart + (w-m.wStart)/(m.Catalyst.Density*m.area)
+}
+
+// flux returns the heat flux through the wall into the process gas (W/m^2) at
+// catalyst mass w and state y, where the process gas next to the wall is the
+// last T.
+func (m *model) flux(w float64, y []float64) float64 {
+	T := y[m.iP()-1]
+	switch {
+	case m.Heating.Adiabatic:
+		return 0
+	case m.Heating.Mode == WallTemperature:
+		return m.fluxFactor() * m.coefficient(y) * (m.Heating.Profile.At(m.z(w)) - T)
+	case m.Heating.Mode == HeatFlux:
+		return m.fluxFactor() * m.Heating.Profile.At(m.z(w))
+	case m.Heating.Mode == Radiation:
+		// the factor scales the exchange, as for a partial view of the flame
+		q, _ := m.radiantFlux(y[m.iTα()], T, m.coefficient(y))
+		return q
+	}
+	return m.fluxFactor() * m.coefficient(y) * (y[m.iTα()] - T)
+}
+
+// fluxFactor returns Heating.FluxFactor, of which zero leaves the flux
+// unchanged.
+func (m *model) fluxFactor() float64 {
+	if m.Heating.FluxFactor == 0 {
+		return 1
+	}
+	return m.Heating.FluxFactor
+}
+
+// prescribed reports whether the wall temperature or flux is given, rather
+// than found from a heating gas balance.
+func (m *model) prescribed() bool {
+	return m.Heating.Mode == WallTemperature || m.Heating.Mode == HeatFlux
+}
@@ -0,0 +1,29 @@
+package reactor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	table, err := ReadTable(strings.NewReader("z (m), Tw (K)\n0, 1000\n2, 1100\n6, 1100\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct{ z, value float64 }{
+		{-1, 1000},
+		{0, 1000},
+		{1, 1050},
+		{4, 1100},
+		{8, 1100},
+	} {
+		if v := table.At(test.z); v != test.value {
+			t.Errorf("At(%g): expected %g; got %g", test.z, test.value, v)
+		}
+	}
+	for _, bad := range []string{"", "0, 1\n0, 2\n", "0, 1\nx, 2\n"} {
+		if _, err := ReadTable(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
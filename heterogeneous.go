@@ -0,0 +1,220 @@
+package reactor
+
+import (
+	"math"
+
+	"github.com/ewancook/reactor/units"
+)
+
+// The heterogeneous bed holds the catalyst at its own temperature and
+// composition, found from quasi-steady pellet balances: each species is
+// produced at the surface at the rate it crosses the film to the bulk gas,
+// and the heat absorbed by the reactions at the catalyst temperature is
+// supplied by transfer from the bulk gas. Species cross the film at the
+// catalyst temperature, so the gas itself sees the heats of reaction at its
+// own temperature and energy is conserved exactly.
+
+// wakaoKaguei returns the gas–solid heat transfer coefficient (W/m^2K) of a
+// packed bed from Nu = 2 + 1.1 Pr^(1/3) Re^0.6, where Re and Nu are based on
+// the particle diameter dp and k is the gas conductivity.
+func wakaoKaguei(Re, Pr, k, dp float64) float64 {
+	return (2 + 1.1*math.Cbrt(Pr)*math.Pow(Re, 0.6)) * k / dp
+}
+
+// wakaoFunazkri returns the gas–solid mass transfer coefficient (m/s) of a
+// packed bed from Sh = 2 + 1.1 Sc^(1/3) Re^0.6, where Re and Sh are based on
+// the particle diameter dp and D is the diffusivity of the reactants.
+func wakaoFunazkri(Re, Sc, D, dp float64) float64 {
+	return (2 + 1.1*math.Cbrt(Sc)*math.Pow(Re, 0.6)) * D / dp
+}
+
+// transfer returns the gas–solid heat (W/K/kg) and mass (m^3/s/kg) transfer
+// coefficients per kg of catalyst for the process gas state y.
+func (m *model) transfer(y []float64) (hv, kv float64) {
+	massFlow := m.mech.massFlow(y) / 1000
+	capacity := m.mech.heatCapacity(y[m.n], y)
+	G := massFlow / m.area
+	Re := G * m.Catalyst.Dp / m.Gas.Viscosity
+	Pr := capacity / massFlow * m.Gas.Viscosity / m.Gas.Conductivity
+	Sc := m.Gas.Viscosity / (m.Gas.Density * m.Gas.Diffusivity)
+	// external area of spherical pellets per kg of catalyst
+	av := 6 * (1 - m.Catalyst.Voidage) / m.Catalyst.Dp / m.Catalyst.Density
+	return wakaoKaguei(Re, Pr, m.Gas.Conductivity, m.Catalyst.Dp) * av,
+		wakaoFunazkri(Re, Sc, m.Gas.Diffusivity, m.Catalyst.Dp) * av
+}
+
+// surface solves the pellet balances for the catalyst temperature and surface
+// partial pressures about the gas temperature T and the bulk partial
+// pressures in m.partials, given the transfer coefficients hv (W/K/kg) and kv
+// (m^3/s/kg). It leaves the surface partial pressures in m.pellet, fills dFdW
+// with the production rates at the surface and returns the catalyst
+// temperature with the rate at which the reactions absorb heat from the gas.
+func (m *model) surface(T units.Temperature, hv, kv float64, dFdW []float64) (units.Temperature, units.Energy) {
+	// the partial pressure difference across the film per unit production
+	film := (units.Pressure(R*T.In(units.K)/kv) * units.Pa).In(m.mech.pressure)
+	for i, p := range m.partials {
+		m.pellet[i] = math.Max(p, 0)
+	}
+	m.pelletΔτ = 1e-3
+	balance := func(Ts units.Temperature) float64 {
+		m.filmPartials(Ts, film)
+		heats := m.mech.derivatives(Ts, m.pellet, m.rates, dFdW)
+		return hv*(Ts-T).In(units.K) + heats.In(units.J)
+	}
+	const δ = 1e-3 * units.K
+	Ts := T
+	for iteration := 0; iteration < 50; iteration++ {
+		g, gδ := balance(Ts), balance(Ts+δ)
+		step := g / ((gδ - g) / δ.In(units.K))
+		// a bounded Newton step keeps the iteration away from the runaway
+		// branches of strongly exothermic rates
+		step = math.Max(-50, math.Min(50, step))
+		Ts -= units.Temperature(step) * units.K
+		if math.Abs(step) < 1e-8 {
+			break
+		}
+	}
+	m.filmPartials(Ts, film)
+	return Ts, m.mech.derivativesAt(Ts, T, m.pellet, m.rates, dFdW)
+}
+
+// filmPartials solves the film balances at catalyst temperature Ts for the
+// surface partial pressures, starting from those in m.pellet. The film is
+// dilute: each species crosses it at the mass transfer coefficient times the
+// difference between its surface and bulk concentrations, both taken at the
+// gas temperature, so that the difference in partial pressure is film times
+// the production rate. Newton's method stalls from the bulk state where the
+// film limits the rates, so the surface is relaxed towards the balances in
+// implicit steps of a pseudo time, m.pelletΔτ, that lengthen into Newton
+// steps as the residuals fall.
+func (m *model) filmPartials(Ts units.Temperature, film float64) {
+	x, g, step, trial, J := m.pellet, m.pelletResidual, m.pelletStep, m.pelletTrial, m.pelletMatrix
+	var total float64
+	for _, p := range m.partials {
+		total += math.Max(p, 0)
+	}
+	balances := func(x, g []float64) float64 {
+		m.mech.derivatives(Ts, x, m.rates, m.pelletRates)
+		var sum float64
+		for i := range x {
+			g[i] = x[i] - m.partials[i] - film*m.pelletRates[i]
+			sum += g[i] * g[i]
+		}
+		return math.Sqrt(sum) / total
+	}
+	current := balances(x, g)
+	for iteration := 0; iteration < 500 && current > 1e-12; iteration++ {
+		m.filmJacobian(J, Ts, film, x)
+		for i := range x {
+			J[i][i] += 1 / m.pelletΔτ
+		}
+		copy(step, g)
+		if !solveDense(J, step) {
+			return
+		}
+		for i := range x {
+			trial[i] = math.Max(x[i]-step[i], 0.1*x[i])
+		}
+		// no partial pressure falls by more than nine tenths in a step, and
+		// steps that more than double the residuals are retaken shorter
+		next := balances(trial, step)
+		if !(next < 2*current) {
+			m.pelletΔτ /= 4
+			continue
+		}
+		copy(x, trial)
+		copy(g, step)
+		m.pelletΔτ = math.Min(m.pelletΔτ*math.Max(2, current/next), 1e12)
+		current = next
+	}
+}
+
+// filmJacobian fills J with the derivatives of the film balances of
+// filmPartials with respect to the surface partial pressures x.
+func (m *model) filmJacobian(J [][]float64, Ts units.Temperature, film float64, x []float64) {
+	partials, rates := m.partialJets, m.rateJets
+	for i := range partials {
+		partials[i] = variable(x[i], 1+i)
+	}
+	m.mech.rateJets(constant(Ts.In(units.K)), partials, rates)
+	for i := range J {
+		for l := range J[i] {
+			J[i][l] = 0
+		}
+		J[i][i] = 1
+	}
+	for j, ν := range m.mech.ν {
+		for i := range ν {
+			for l := range x {
+				J[i][l] -= film * ν[i] * rates[j].d[1+l]
+			}
+		}
+	}
+}
+
+// solveDense overwrites b with the solution of A x = b by Gaussian
+// elimination with partial pivoting, destroying A. It reports false if A is
+// singular.
+func solveDense(A [][]float64, b []float64) bool {
+	n := len(b)
+	for k := 0; k < n; k++ {
+		pivot := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(A[i][k]) > math.Abs(A[pivot][k]) {
+				pivot = i
+			}
+		}
+		if A[pivot][k] == 0 {
+			return false
+		}
+		A[k], A[pivot] = A[pivot], A[k]
+		b[k], b[pivot] = b[pivot], b[k]
+		for i := k + 1; i < n; i++ {
+			factor := A[i][k] / A[k][k]
+			for j := k; j < n; j++ {
+				A[i][j] -= factor * A[k][j]
+			}
+			b[i] -= factor * b[k]
+		}
+	}
+	for k := n - 1; k >= 0; k-- {
+		for j := k + 1; j < n; j++ {
+			b[k] -= A[k][j] * b[j]
+		}
+		b[k] /= A[k][k]
+	}
+	return true
+}
+
+// solidProfile returns the catalyst state at every step of a transposed
+// solver table, as a table of the same form whose species rows hold the
+// surface partial pressures and P row their sum (kPa), so that partials
+// recovers them, with the catalyst temperature (K) in place of T.
+func (m *model) solidProfile(yValues [][]float64) [][]float64 {
+	n, steps := m.n, len(yValues[0])
+	solid := make([][]float64, len(yValues))
+	for i := range solid {
+		solid[i] = make([]float64, steps)
+	}
+	y := make([]float64, len(yValues))
+	dFdW := make([]float64, n)
+	toKPa := (units.Pressure(1) * m.mech.pressure).In(units.KPa)
+	for k := 0; k < steps; k++ {
+		for i := range y {
+			y[i] = yValues[i][k]
+		}
+		T := units.Temperature(y[n]) * units.K
+		m.mech.partials(y, m.partials)
+		hv, kv := m.transfer(y)
+		Ts, _ := m.surface(T, hv, kv, dFdW)
+		for i, p := range m.pellet {
+			solid[i][k] = p
+			solid[n+1][k] += p * toKPa
+		}
+		solid[n][k] = Ts.In(units.K)
+		for i := n + 2; i < len(y); i++ {
+			solid[i][k] = y[i]
+		}
+	}
+	return solid
+}
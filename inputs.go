@@ -0,0 +1,174 @@
+package reactor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Input returns the value of the named input of c. Inputs are named as a
+// process species (its total feed, mol/s), T or P (the inlet temperature and
+// pressure), Talpha, U, ho (Heating.Outside), Dp (the particle diameter),
+// voidage, tubes, D or l (the tube diameter and length), wall-thickness,
+// wall-conductivity, "flue:" and a heating gas species (mol/s), or "k" and
+// the number of a reaction of the mechanism, whose value multiplies the rate
+// constant of that reaction as in Kinetics.Factors. The length of a zoned
+// tube is that of its zones, so l is not an input of one.
+func (c Config) Input(name string) (float64, error) {
+	switch {
+	case name == "T":
+		return c.T, nil
+	case name == "P":
+		return c.P, nil
+	case name == "Talpha":
+		return c.Heating.Talpha, nil
+	case name == "U":
+		return c.Heating.U, nil
+	case name == "Dp":
+		return c.Catalyst.Dp, nil
+	case name == "voidage":
+		return c.Catalyst.Voidage, nil
+	case name == "tubes":
+		return c.Geometry.Tubes, nil
+	case name == "ho":
+		return c.Heating.Outside, nil
+	case name == "D":
+		return c.Geometry.D, nil
+	case name == "l":
+		if c.Zones != nil {
+			return 0, fmt.Errorf("reactor: the length of a zoned tube is set by its zones")
+		}
+		return c.Geometry.Length, nil
+	case name == "wall-thickness":
+		return c.Wall.Thickness, nil
+	case name == "wall-conductivity":
+		return c.Wall.Conductivity, nil
+	case strings.HasPrefix(name, "flue:"):
+		compound := strings.TrimPrefix(name, "flue:")
+		if !contains(flueSpecies, compound) {
+			return 0, fmt.Errorf("reactor: %s is not a heating gas species", compound)
+		}
+		return c.Flue[compound], nil
+	}
+	j, ok, err := c.reactionInput(name)
+	switch {
+	case err != nil:
+		return 0, err
+	case !ok:
+		mech, err := mechanismFor(c.Mode)
+		if err != nil {
+			return 0, err
+		}
+		if mech.index(name) < 0 {
+			return 0, fmt.Errorf("reactor: unknown input %q", name)
+		}
+		return c.Feed[name], nil
+	case c.Kinetics.Factors == nil:
+		return 1, nil
+	}
+	return c.Kinetics.Factors[j], nil
+}
+
+// WithInput returns a copy of c with the named input set to v, which shares
+// no maps or slices with c.
+func (c Config) WithInput(name string, v float64) (Config, error) {
+	if _, err := c.Input(name); err != nil {
+		return c, err
+	}
+	c = c.copyInputs()
+	c.setInput(name, v)
+	return c, nil
+}
+
+// copyInputs returns a copy of c that shares no input maps or slices with c.
+func (c Config) copyInputs() Config {
+	feed, flue := map[string]float64{}, map[string]float64{}
+	for compound, flow := range c.Feed {
+		feed[compound] = flow
+	}
+	for compound, flow := range c.Flue {
+		flue[compound] = flow
+	}
+	c.Feed, c.Flue = feed, flue
+	if c.Kinetics.Factors != nil {
+		c.Kinetics.Factors = append([]float64(nil), c.Kinetics.Factors...)
+	}
+	return c
+}
+
+// setInput sets the named input of c, checked by Input, to v in place, so c
+// must not share its maps or slices with another Config.
+func (c *Config) setInput(name string, v float64) {
+	switch {
+	case name == "T":
+		c.T = v
+	case name == "P":
+		c.P = v
+	case name == "Talpha":
+		c.Heating.Talpha = v
+	case name == "U":
+		c.Heating.U = v
+	case name == "Dp":
+		c.Catalyst.Dp = v
+	case name == "voidage":
+		c.Catalyst.Voidage = v
+	case name == "tubes":
+		c.Geometry.Tubes = v
+	case name == "ho":
+		c.Heating.Outside = v
+	case name == "D":
+		c.Geometry.D = v
+	case name == "l":
+		c.Geometry.Length = v
+	case name == "wall-thickness":
+		c.Wall.Thickness = v
+	case name == "wall-conductivity":
+		c.Wall.Conductivity = v
+	case strings.HasPrefix(name, "flue:"):
+		c.Flue[strings.TrimPrefix(name, "flue:")] = v
+	default:
+		j, ok, _ := c.reactionInput(name)
+		if !ok {
+			c.Feed[name] = v
+			return
+		}
+		if c.Kinetics.Factors == nil {
+			mech, _ := mechanismFor(c.Mode)
+			c.Kinetics.Factors = make([]float64, len(mech.reactions))
+			for i := range c.Kinetics.Factors {
+				c.Kinetics.Factors[i] = 1
+			}
+		}
+		c.Kinetics.Factors[j] = v
+	}
+}
+
+// massInput reports whether the named input changes the catalyst mass of the
+// tube, along which the state is found.
+func massInput(name string) bool {
+	return name == "D" || name == "l"
+}
+
+// reactionInput reports whether name is "k" and a reaction number, and if so
+// returns the index of that reaction, or an error if the mechanism of c does
+// not have it.
+func (c Config) reactionInput(name string) (int, bool, error) {
+	if !strings.HasPrefix(name, "k") {
+		return 0, false, nil
+	}
+	j, err := strconv.Atoi(strings.TrimPrefix(name, "k"))
+	if err != nil {
+		return 0, false, nil
+	}
+	mech, err := mechanismFor(c.Mode)
+	if err != nil {
+		return 0, true, err
+	}
+	if j < 1 || j > len(mech.reactions) {
+		return 0, true, fmt.Errorf("reactor: %s: the %s mechanism has %d reactions", name, c.Mode, len(mech.reactions))
+	}
+	if f := c.Kinetics.Factors; f != nil && len(f) != len(mech.reactions) {
+		return 0, true, fmt.Errorf("reactor: %d kinetic factors given for %d reactions", len(f), len(mech.reactions))
+	}
+	return j - 1, true, nil
+}
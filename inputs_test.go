@@ -0,0 +1,35 @@
+package reactor
+
+import "testing"
+
+func TestWithInput(t *testing.T) {
+	c := DefaultConfig()
+	for name, v := range map[string]float64{
+		"T": 900, "Talpha": 1500, "U": 60, "ho": 60, "Dp": 0.01, "tubes": 150, "D": 0.12, "l": 10,
+		"wall-thickness": 0.01, "wall-conductivity": 20, "flue:N2": 700, "CH4": 90, "k2": 0.5,
+	} {
+		modified, err := c.WithInput(name, v)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, err := modified.Input(name); err != nil || got != v {
+			t.Errorf("%s: expected %g; got %g (%v)", name, v, got, err)
+		}
+	}
+	if c.Feed["CH4"] != 106 || c.Flue["N2"] != 738.5 || c.Kinetics.Factors != nil {
+		t.Error("expected WithInput to leave the original Config alone")
+	}
+	modified, _ := c.WithInput("k2", 0.5)
+	if f := modified.Kinetics.Factors; len(f) != 4 || f[0] != 1 || f[1] != 0.5 {
+		t.Errorf("expected factors of one for the other reactions; got %v", f)
+	}
+	for _, name := range []string{"k5", "NH3", "flue:Ar", "Tin"} {
+		if _, err := c.WithInput(name, 1); err == nil {
+			t.Errorf("%s: expected an error for an unknown input", name)
+		}
+	}
+	c.Zones = []Zone{{Length: 12}}
+	if _, err := c.WithInput("l", 10); err == nil {
+		t.Error("expected an error for the length of a zoned tube")
+	}
+}
@@ -0,0 +1,213 @@
+package reactor
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/ode"
+	"github.com/ewancook/reactor/units"
+)
+
+// jacobian returns the Jacobian of ODEs for the plug flow bed when
+// Solver.AnalyticJacobian is set, or nil, which leaves the solver to
+// approximate it by finite differences. The rates are differentiated as jets
+// and the rest of the balances by hand, except that the heat flux is
+// differenced where U comes from a wall correlation or the heating gas
+// radiates.
+func (m *model) jacobian() ode.JacF {
+	if !m.analytic() {
+		return nil
+	}
+	N := m.iTα() + 1
+	J := make([][]float64, N)
+	for i := range J {
+		J[i] = make([]float64, N)
+	}
+	f := make([]float64, N)
+	return func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		for i := range J {
+			for j := range J[i] {
+				J[i][j] = 0
+			}
+		}
+		m.ODEs(f, h, x, y)
+		m.fillJacobian(J, f, x, y)
+		if dfdy.Max() == 0 {
+			dfdy.Init(N, N, N*N)
+		}
+		dfdy.Start()
+		for i := range J {
+			for j, v := range J[i] {
+				if v != 0 {
+					dfdy.Put(i, j, v)
+				}
+			}
+		}
+	}
+}
+
+// analytic reports whether fillJacobian applies to m and is selected.
+func (m *model) analytic() bool {
+	return m.Solver.AnalyticJacobian && m.rings == 1 && m.Bed != Heterogeneous && m.zones == nil
+}
+
+// stateJacobian fills J with the derivatives of ODEs at catalyst mass w and
+// state y, whose gradients it stores in f. They are analytical where the
+// zone holding w allows, and forward differences otherwise.
+func (m *model) stateJacobian(J [][]float64, f []float64, w float64, y []float64) {
+	m.ODEs(f, 0, w, y)
+	zone := m
+	if m.zones != nil {
+		zone = m.zone(w)
+	}
+	if zone.analytic() {
+		for i := range J {
+			for j := range J[i] {
+				J[i][j] = 0
+			}
+		}
+		zone.fillJacobian(J, f, w, y)
+		return
+	}
+	perturbed := append([]float64(nil), y...)
+	f1 := make([]float64, len(f))
+	for l := range y {
+		δ := math.Sqrt(1e-16) * math.Max(math.Abs(y[l]), 1e-5)
+		perturbed[l] = y[l] + δ
+		m.ODEs(f1, 0, w, perturbed)
+		perturbed[l] = y[l]
+		for i := range J {
+			J[i][l] = (f1[i] - f[i]) / δ
+		}
+	}
+}
+
+// fillJacobian adds the derivatives of the plug flow gradients f at catalyst
+// mass w and state y to the zeroed matrix J.
+func (m *model) fillJacobian(J [][]float64, f []float64, w float64, y []float64) {
+	n := m.n
+	iT, iP, iTα := n, n+1, n+2
+	T, P := y[iT], y[iP]
+	var Ft, dCdT float64
+	for i, slope := range m.mech.cpSlope {
+		Ft += y[i]
+		dCdT += slope(T) * y[i]
+	}
+	C := m.mech.heatCapacity(T, y)
+
+	// rates and their derivatives with respect to T and the partial
+	// pressures, which follow from the flows and P
+	cf := (units.Pressure(1) * units.KPa).In(m.mech.pressure)
+	partials, rates, dr := m.partialJets, m.rateJets, m.dr
+	for i := range partials {
+		partials[i] = variable(cf*P*y[i]/Ft, 1+i)
+	}
+	m.mech.rateJets(variable(T, 0), partials, rates)
+	for j, r := range rates {
+		for l := range dr[j] {
+			dr[j][l] = 0
+		}
+		dr[j][iT] = r.d[0]
+		for k := 0; k < n; k++ {
+			dpdP := cf * y[k] / Ft
+			dr[j][iP] += r.d[1+k] * dpdP
+			for l := 0; l < n; l++ {
+				dpdF := -dpdP * P / Ft
+				if k == l {
+					dpdF += cf * P / Ft
+				}
+				dr[j][l] += r.d[1+k] * dpdF
+			}
+		}
+	}
+
+	// species
+	for j, ν := range m.mech.ν {
+		for i := range ν {
+			for l := range dr[j] {
+				J[i][l] += ν[i] * dr[j][l]
+			}
+		}
+	}
+
+	// temperature, from the heats of reaction (J) and the heat flux
+	Q := 4 / m.Geometry.D / m.Catalyst.Density
+	dq := m.fluxJacobian(w, y)
+	for j, ν := range m.mech.ν {
+		ΔH := m.mech.enthalpy(j, T) * 1000
+		dΔHdT := m.mech.heatCapacity(T, ν) // J/molK
+		for l := range dr[j] {
+			J[iT][l] -= ΔH * dr[j][l] / C
+		}
+		J[iT][iT] -= rates[j].v * dΔHdT / C
+	}
+	for l := range dq {
+		J[iT][l] += Q * dq[l] / C
+	}
+	for i, cp := range m.mech.cp {
+		J[iT][i] -= f[iT] * cp(T) / C
+	}
+	J[iT][iT] -= f[iT] * dCdT / C
+
+	// pressure, by the Ergun equation, where β depends on the mass flux G
+	ϕ := m.Catalyst.Voidage
+	G := m.mech.massFlow(y) / 1000 / m.area
+	b := 150 * (1 - ϕ) * m.Gas.Viscosity / m.Catalyst.Dp
+	dlnβdG := 1/G + 1.75/(1.75*G+b)
+	for i, M := range m.mech.molarMass {
+		J[iP][i] = f[iP]/Ft + f[iP]*dlnβdG*M/1000/m.area
+	}
+	J[iP][iT] = f[iP] / T
+	J[iP][iP] = -f[iP] / P
+
+	// heating gas
+	if m.prescribed() || m.Mode == Methanation {
+		return
+	}
+	var aveCP, slope float64
+	for i, cp := range flueCP {
+		aveCP += cp(y[iTα]) * m.flue[i]
+		slope += flueCPSlope[i](y[iTα]) * m.flue[i]
+	}
+	aveCP /= m.totalFlue
+	slope /= m.totalFlue
+	sign := 1.0
+	if m.counterCurrent() {
+		sign = -1
+	}
+	for l := range dq {
+		J[iTα][l] = -sign * Q * dq[l] / (m.totalFlue * aveCP) * m.Geometry.Tubes
+	}
+	J[iTα][iTα] -= f[iTα] * slope / aveCP
+}
+
+// fluxJacobian returns the derivatives of the heat flux into the process gas
+// (W/m^2) with respect to the plug flow state y at catalyst mass w, in space
+// reused by the next call.
+func (m *model) fluxJacobian(w float64, y []float64) []float64 {
+	dq := m.dq
+	for l := range dq {
+		dq[l] = 0
+	}
+	iT := m.iP() - 1
+	switch {
+	case m.Heating.Adiabatic || m.Heating.Mode == HeatFlux:
+		return dq
+	case m.Heating.Correlation != "" || m.Heating.Mode == Radiation:
+		q := m.flux(w, y)
+		perturbed := m.perturbed
+		copy(perturbed, y)
+		for l := range y {
+			δ := math.Sqrt(1e-16) * math.Max(math.Abs(y[l]), 1e-5)
+			perturbed[l] = y[l] + δ
+			dq[l] = (m.flux(w, perturbed) - q) / δ
+			perturbed[l] = y[l]
+		}
+		return dq
+	}
+	dq[iT] = -m.fluxFactor() * m.U
+	if m.Heating.Mode != WallTemperature {
+		dq[m.iTα()] = m.fluxFactor() * m.U
+	}
+	return dq
+}
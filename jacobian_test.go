@@ -0,0 +1,215 @@
+package reactor
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/ode"
+)
+
+func TestRateJets(t *testing.T) {
+	T := variable(1000, 0)
+	for _, mech := range []mechanism{steamReforming, ammoniaCracking} {
+		partials, jets := make([]float64, len(mech.species)), make([]jet, len(mech.species))
+		for i := range partials {
+			partials[i] = float64(i + 1)
+			jets[i] = variable(partials[i], 1+i)
+		}
+		rates, rateJets := make([]float64, len(mech.reactions)), make([]jet, len(mech.reactions))
+		mech.rates(T.v, partials, rates)
+		mech.rateJets(T, jets, rateJets)
+		for j := range rates {
+			if math.Abs(rateJets[j].v-rates[j]) > 1e-12*math.Abs(rates[j]) {
+				t.Errorf("%s reaction %d: jet rate %g differs from rate %g", mech.keyName, j+1, rateJets[j].v, rates[j])
+			}
+		}
+	}
+}
+
+func TestJacobian(t *testing.T) {
+	methanation := DefaultConfig()
+	methanation.Mode, methanation.T, methanation.P = Methanation, 573.15, 3000
+	methanation.Heating.Talpha = 573.15
+	methanation.Feed = map[string]float64{"CO": 0.5, "CO2": 0.3, "H2": 100, "CH4": 1, "H2O": 0.1}
+
+	cracking := DefaultConfig()
+	cracking.Mode, cracking.Feed = Cracking, map[string]float64{"NH3": 100}
+
+	configs := map[string]Config{
+		"reforming":    DefaultConfig(),
+		"methanation":  methanation,
+		"cracking":     cracking,
+		"counter":      DefaultConfig(),
+		"wall":         DefaultConfig(),
+		"flux":         DefaultConfig(),
+		"radiation":    DefaultConfig(),
+		"correlation":  DefaultConfig(),
+		"kinetics":     DefaultConfig(),
+		"flux factor":  DefaultConfig(),
+		"adiabatic":    DefaultConfig(),
+		"cracking gas": cracking,
+	}
+	c := configs["counter"]
+	c.Heating.Arrangement, c.Heating.Talpha = CounterCurrent, 1300
+	configs["counter"] = c
+	c = configs["wall"]
+	c.Heating.Mode, c.Heating.Profile = WallTemperature, Table{Z: []float64{0, 15}, Values: []float64{1000, 1150}}
+	configs["wall"] = c
+	c = configs["flux"]
+	c.Heating.Mode, c.Heating.Profile = HeatFlux, Table{Z: []float64{0}, Values: []float64{60000}}
+	configs["flux"] = c
+	c = configs["radiation"]
+	c.Heating.Mode, c.Heating.U = Radiation, 500
+	configs["radiation"] = c
+	c = configs["correlation"]
+	c.Heating.Correlation = Leva
+	configs["correlation"] = c
+	c = configs["kinetics"]
+	c.Kinetics.Factors = []float64{0.5, 1, 2, 0.8}
+	configs["kinetics"] = c
+	c = configs["flux factor"]
+	c.Heating.FluxFactor = 1.2
+	configs["flux factor"] = c
+	c = configs["adiabatic"]
+	c.Heating.Adiabatic = true
+	configs["adiabatic"] = c
+	c = configs["cracking gas"]
+	c.Feed = map[string]float64{"NH3": 80, "N2": 5, "H2": 15}
+	configs["cracking gas"] = c
+
+	for name, c := range configs {
+		m, err := newModel(c)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		wValues, yValues, err := m.integrate(context.Background(), m.initial())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		// compare away from the inlet, where the heating gas enters at the
+		// end of a range of the specific heat correlations
+		for _, k := range []int{len(wValues) / 10, len(wValues) / 2} {
+			y := make([]float64, len(yValues))
+			for i := range y {
+				y[i] = yValues[i][k]
+			}
+			compareJacobian(t, name, m, wValues[k], y)
+		}
+	}
+}
+
+func TestAnalyticJacobianOption(t *testing.T) {
+	c := DefaultConfig()
+	m, err := newModel(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.jacobian() != nil {
+		t.Error("expected finite differences by default")
+	}
+	plain, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Solver.AnalyticJacobian = true
+	if m, err = newModel(c); err != nil {
+		t.Fatal(err)
+	}
+	if m.jacobian() == nil {
+		t.Error("expected the analytical Jacobian when selected")
+	}
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Δ := math.Abs(p.T[len(p.T)-1] - plain.T[len(plain.T)-1]); Δ > 0.05 {
+		t.Errorf("expected the Jacobian not to change the outlet temperature; differs by %f K", Δ)
+	}
+}
+
+// compareJacobian checks the analytical Jacobian of m at w and y against
+// central differences of its gradients.
+func compareJacobian(t *testing.T, name string, m *model, w float64, y []float64) {
+	N := len(y)
+	J := make([][]float64, N)
+	for i := range J {
+		J[i] = make([]float64, N)
+	}
+	f := make([]float64, N)
+	m.ODEs(f, 0, w, y)
+	m.fillJacobian(J, f, w, y)
+
+	fPlus, fMinus := make([]float64, N), make([]float64, N)
+	perturbed := append([]float64(nil), y...)
+	numerical := make([][]float64, N)
+	for i := range numerical {
+		numerical[i] = make([]float64, N)
+	}
+	for l := range y {
+		δ := 1e-6 * math.Max(math.Abs(y[l]), 1e-3)
+		perturbed[l] = y[l] + δ
+		m.ODEs(fPlus, 0, w, perturbed)
+		perturbed[l] = y[l] - δ
+		m.ODEs(fMinus, 0, w, perturbed)
+		perturbed[l] = y[l]
+		for i := range numerical {
+			numerical[i][l] = (fPlus[i] - fMinus[i]) / (2 * δ)
+		}
+	}
+	for i := range J {
+		// the entries of a row are compared on the scale of its largest term
+		// in the change of f over a relative change in y
+		var scale float64
+		for l := range J[i] {
+			scale = math.Max(scale, math.Abs(numerical[i][l]*math.Max(math.Abs(y[l]), 1e-3)))
+		}
+		for l := range J[i] {
+			err := math.Abs(J[i][l]-numerical[i][l]) * math.Max(math.Abs(y[l]), 1e-3)
+			if err > 1e-4*scale+1e-12 {
+				t.Errorf("%s: at %.3g kg, d f[%d]/d y[%d] is %g; finite differences give %g", name, w, i, l, J[i][l], numerical[i][l])
+			}
+		}
+	}
+}
+
+func BenchmarkJacobian(b *testing.B) {
+	m, err := newModel(DefaultConfig())
+	if err != nil {
+		b.Fatal(err)
+	}
+	y := m.initial()
+	N := len(y)
+	J := make([][]float64, N)
+	for i := range J {
+		J[i] = make([]float64, N)
+	}
+	f := make([]float64, N)
+	m.ODEs(f, 0, 0, y)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.fillJacobian(J, f, 0, y)
+	}
+}
+
+// BenchmarkSolve integrates the default tube with the analytical Jacobian
+// and with the finite differences the solver falls back on without it.
+func BenchmarkSolve(b *testing.B) {
+	c := DefaultConfig()
+	c.Solver.AnalyticJacobian = true
+	m, err := newModel(c)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for name, jac := range map[string]ode.JacF{"analytic": m.jacobian(), "finite differences": nil} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := m.solve(context.Background(), m.ODEs, jac, m.initial(), 0, m.W, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package reactor
+
+import (
+	"math"
+)
+
+// jetSize is the number of derivatives carried by a jet: temperature followed
+// by the partial pressure of each species of a mechanism, of which there are
+// at most six.
+const jetSize = 7
+
+// jet is a value with its derivatives with respect to jetSize variables, which
+// differentiates the rate laws in forward mode for the analytical Jacobian.
+type jet struct {
+	v float64
+	d [jetSize]float64
+}
+
+// constant returns a jet with no derivatives.
+func constant(v float64) jet {
+	return jet{v: v}
+}
+
+// variable returns a jet that is variable i itself.
+func variable(v float64, i int) jet {
+	x := jet{v: v}
+	x.d[i] = 1
+	return x
+}
+
+// apply returns f(a), given v = f(a.v) and its slope f'(a.v).
+func (a jet) apply(v, slope float64) jet {
+	c := jet{v: v}
+	for i := range c.d {
+		c.d[i] = slope * a.d[i]
+	}
+	return c
+}
+
+func (a jet) add(b jet) jet {
+	for i := range a.d {
+		a.d[i] += b.d[i]
+	}
+	a.v += b.v
+	return a
+}
+
+func (a jet) sub(b jet) jet {
+	return a.add(b.scale(-1))
+}
+
+// plus adds a constant.
+func (a jet) plus(c float64) jet {
+	a.v += c
+	return a
+}
+
+func (a jet) scale(c float64) jet {
+	return a.apply(a.v*c, c)
+}
+
+func (a jet) mul(b jet) jet {
+	c := jet{v: a.v * b.v}
+	for i := range c.d {
+		c.d[i] = a.d[i]*b.v + a.v*b.d[i]
+	}
+	return c
+}
+
+func (a jet) div(b jet) jet {
+	c := jet{v: a.v / b.v}
+	for i := range c.d {
+		c.d[i] = (a.d[i] - c.v*b.d[i]) / b.v
+	}
+	return c
+}
+
+func (a jet) pow(e float64) jet {
+	return a.apply(math.Pow(a.v, e), e*math.Pow(a.v, e-1))
+}
+
+// atLeast is math.Max(a, bound), which is constant below the bound.
+func (a jet) atLeast(bound float64) jet {
+	if a.v < bound {
+		return constant(bound)
+	}
+	return a
+}
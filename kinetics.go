@@ -1,45 +1,121 @@
-package main
+package reactor
 
-import "math"
+import (
+	"math"
 
-const R = 8.314
+	"github.com/ewancook/reactor/thermo"
+)
+
+// R is the gas constant (J/molK), as used by the thermo package.
+const R = thermo.R
+
+// Activation energies and heats of adsorption (J/mol), such that each
+// constant varies as exp(-e/RT).
+const (
+	e1   = 209200
+	e2   = 15400
+	e3   = 109400
+	e4   = 75800
+	e5   = 150000
+	eCO  = -140000
+	eH2  = -93400
+	eH2O = 15900
+)
+
+// Equilibrium temperatures (K), such that kp varies as exp(-θ/T).
+const (
+	θ1 = 26830
+	θ2 = -4400
+	θ3 = 22430
+)
 
 func k1(T float64) float64 {
-	return 5.922 * math.Pow(10, 8) * math.Exp(-209200/R/T)
+	return 5.922 * math.Pow(10, 8) * math.Exp(-e1/R/T)
 }
 
 func k2(T float64) float64 {
-	return 6.028 * math.Pow(10, -4) * math.Exp(-15400/R/T)
+	return 6.028 * math.Pow(10, -4) * math.Exp(-e2/R/T)
 }
 
 func k3(T float64) float64 {
-	return 1.093 * math.Pow(10, 3) * math.Exp(-109400/R/T)
+	return 1.093 * math.Pow(10, 3) * math.Exp(-e3/R/T)
 }
 
+// k4 is per bar of ethane; the original fit was per kPa.
 func k4(T float64) float64 {
-	return 8 * math.Pow(10, 5) * math.Exp(-75800/R/T) //-75800
+	return 8 * math.Pow(10, 7) * math.Exp(-e4/R/T)
+}
+
+// k5 is representative of ammonia decomposition over a nickel reforming
+// catalyst (mol/s/kg bar^0.5).
+func k5(T float64) float64 {
+	return 5 * math.Pow(10, 7) * math.Exp(-e5/R/T)
 }
 
 func kCO(T float64) float64 {
-	return 5.127 * math.Pow(10, -13) * math.Exp(140000/R/T)
+	return 5.127 * math.Pow(10, -13) * math.Exp(-eCO/R/T)
 }
 
 func kH2(T float64) float64 {
-	return 5.68 * math.Pow(10, -10) * math.Exp(93400/R/T)
+	return 5.68 * math.Pow(10, -10) * math.Exp(-eH2/R/T)
 }
 
 func kH2O(T float64) float64 {
-	return 9.251 * math.Exp(-15900/R/T)
+	return 9.251 * math.Exp(-eH2O/R/T)
 }
 
 func kp1(T float64) float64 {
-	return 1.2 * math.Pow(10, 17) * math.Exp(-26830/T)
+	return 1.2 * math.Pow(10, 17) * math.Exp(-θ1/T)
 }
 
 func kp2(T float64) float64 {
-	return 1.8 * math.Pow(10, -2) * math.Exp(4400/T)
+	return 1.8 * math.Pow(10, -2) * math.Exp(-θ2/T)
 }
 
 func kp3(T float64) float64 {
-	return 2.1 * math.Pow(10, 15) * math.Exp(-22430/T)
+	return 2.1 * math.Pow(10, 15) * math.Exp(-θ3/T)
+}
+
+// log10Ka is the Gillespie–Beattie correlation for the equilibrium constant of
+// ammonia synthesis (atm^-1), with its slope.
+func log10Ka(T float64) (v, slope float64) {
+	v = -2.691122*math.Log10(T) - 5.519265*math.Pow(10, -5)*T + 1.848863*math.Pow(10, -7)*T*T + 2001.6/T + 2.6899
+	slope = -2.691122/T/math.Ln10 - 5.519265*math.Pow(10, -5) + 2*1.848863*math.Pow(10, -7)*T - 2001.6/T/T
+	return v, slope
+}
+
+// kp5 is the equilibrium constant of 2NH3 = N2 + 3H2 (bar^2), from the
+// Gillespie–Beattie correlation for ammonia synthesis (atm^-1).
+func kp5(T float64) float64 {
+	v, _ := log10Ka(T)
+	return math.Pow(1.01325, 2) / math.Pow(10, 2*v)
+}
+
+// arrhenius returns the jet of k, which varies as exp(-e/RT), at T.
+func arrhenius(k func(float64) float64, e float64, T jet) jet {
+	v := k(T.v)
+	return T.apply(v, v*e/R/T.v/T.v)
+}
+
+// vantHoff returns the jet of kp, which varies as exp(-θ/T), at T.
+func vantHoff(kp func(float64) float64, θ float64, T jet) jet {
+	v := kp(T.v)
+	return T.apply(v, v*θ/T.v/T.v)
+}
+
+func kp5Jet(T jet) jet {
+	v := kp5(T.v)
+	_, slope := log10Ka(T.v)
+	return T.apply(v, -2*math.Ln10*slope*v)
+}
+
+// kCracking is the equilibrium constant of CH4 = C + 2H2 (bar) and
+// kBoudouard that of 2CO = C + CO2 (bar^-1), fitted to JANAF free energies of
+// formation between 800 and 1000 K.
+func kCracking(T float64) float64 {
+	return math.Exp(13.00 - 10658/T)
+}
+
+func kBoudouard(T float64) float64 {
+	return math.Exp(-21.21 + 20646/T)
 }
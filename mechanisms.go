@@ -0,0 +1,247 @@
+package reactor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ewancook/reactor/thermo"
+	"github.com/ewancook/reactor/units"
+)
+
+// Mode selects the duty of the reactor.
+type Mode string
+
+const (
+	// Reforming is steam reforming of methane and ethane, heated by flue gas.
+	Reforming Mode = "reforming"
+	// Methanation runs the reforming reactions in reverse, with the coolant
+	// held at Heating.Talpha.
+	Methanation Mode = "methanation"
+	// Cracking is ammonia decomposition, heated by flue gas.
+	Cracking Mode = "cracking"
+)
+
+// reaction holds the stoichiometry of a single step in a mechanism.
+type reaction struct {
+	stoichiometry map[string]float64
+}
+
+// mechanism is a set of reactions between a fixed list of process gas
+// species. rates fills r with the rate of each reaction (mol/s/kg catalyst)
+// given the partial pressure of each species, in order, in the unit declared
+// by pressure, and rateJets does the same with the derivatives of the rates
+// with respect to T and then each partial pressure. key is the reactant whose
+// conversion is reported.
+//
+// The remaining fields are filled in from the species and reactions by the
+// indexed method, so that the gradients and their Jacobian need not look
+// anything up by name.
+type mechanism struct {
+	species   []string
+	key       string
+	keyName   string
+	reactions []reaction
+	pressure  units.Pressure
+	rates     func(T float64, p, r []float64)
+	rateJets  func(T jet, p, r []jet)
+
+	ν         [][]float64               // stoichiometric coefficients of each reaction
+	molarMass []float64                 // g/mol
+	cp, h     []func(T float64) float64 // J/molK and kJ/mol
+	cpSlope   []func(T float64) float64 // J/molK^2
+}
+
+var steamReforming = mechanism{
+	species:  []string{"CO", "H2", "CH4", "CO2", "H2O", "C2H6"},
+	key:      "CH4",
+	keyName:  "Methane",
+	pressure: units.Bar,
+	reactions: []reaction{
+		{map[string]float64{"CH4": -1, "H2O": -1, "CO": 1, "H2": 3}},
+		{map[string]float64{"CO": -1, "H2O": -1, "CO2": 1, "H2": 1}},
+		{map[string]float64{"CH4": -1, "H2O": -2, "CO2": 1, "H2": 4}},
+		{map[string]float64{"C2H6": -1, "H2O": -2, "CO": 2, "H2": 5}},
+	},
+	rates: func(T float64, p, r []float64) {
+		denominator := _denominator(T, p)
+		r[0] = reaction1(T, denominator, p)
+		r[1] = reaction2(T, denominator, p)
+		r[2] = reaction3(T, denominator, p)
+		r[3] = reaction4(T, p)
+	},
+	rateJets: func(T jet, p, r []jet) {
+		denominator := denominatorJet(T, p)
+		r[0] = reaction1Jet(T, denominator, p)
+		r[1] = reaction2Jet(T, denominator, p)
+		r[2] = reaction3Jet(T, denominator, p)
+		r[3] = reaction4Jet(T, p)
+	},
+}.indexed()
+
+var ammoniaCracking = mechanism{
+	species:  []string{"NH3", "N2", "H2"},
+	key:      "NH3",
+	keyName:  "Ammonia",
+	pressure: units.Bar,
+	reactions: []reaction{
+		{map[string]float64{"NH3": -2, "N2": 1, "H2": 3}},
+	},
+	rates: func(T float64, p, r []float64) {
+		r[0] = reaction5(T, p)
+	},
+	rateJets: func(T jet, p, r []jet) {
+		r[0] = reaction5Jet(T, p)
+	},
+}.indexed()
+
+// mechanismFor returns the mechanism of the reactor mode.
+func mechanismFor(mode Mode) (mechanism, error) {
+	switch mode {
+	case Reforming, Methanation:
+		return steamReforming, nil
+	case Cracking:
+		return ammoniaCracking, nil
+	}
+	return mechanism{}, fmt.Errorf("reactor: unknown mode %q", mode)
+}
+
+// indexed returns m with the stoichiometry and the properties of its species
+// laid out in the order of its species.
+func (m mechanism) indexed() mechanism {
+	n := len(m.species)
+	m.ν = make([][]float64, len(m.reactions))
+	for j, r := range m.reactions {
+		m.ν[j] = make([]float64, n)
+		for i, compound := range m.species {
+			m.ν[j][i] = r.stoichiometry[compound]
+		}
+	}
+	m.molarMass = make([]float64, n)
+	m.cp = specificHeats(m.species)
+	m.cpSlope = specificHeatSlopes(m.species)
+	m.h = make([]func(float64) float64, n)
+	for i, compound := range m.species {
+		m.molarMass[i] = thermo.MolarMass(compound)
+		m.h[i] = thermo.EnthalpyFunc(compound)
+	}
+	return m
+}
+
+// partials fills p with the partial pressure of each species, in the unit of
+// the rate laws, for the process gas state y, which holds the species flows
+// (mol/s) followed by T (K) and P (kPa).
+func (m mechanism) partials(y, p []float64) {
+	n := len(m.species)
+	var totalFlow float64
+	for i := 0; i < n; i++ {
+		totalFlow += y[i]
+	}
+	P := (units.Pressure(y[n+1]) * units.KPa).In(m.pressure)
+	for i := 0; i < n; i++ {
+		p[i] = P * y[i] / totalFlow
+	}
+}
+
+// heatCapacity returns the heat capacity of the process gas flows (W/K).
+func (m mechanism) heatCapacity(T float64, flows []float64) float64 {
+	var capacity float64
+	for i, cp := range m.cp {
+		capacity += cp(T) * flows[i]
+	}
+	return capacity
+}
+
+// massFlow returns the mass flow of the process gas flows (g/s).
+func (m mechanism) massFlow(flows []float64) float64 {
+	var massFlow float64
+	for i, M := range m.molarMass {
+		massFlow += M * flows[i]
+	}
+	return massFlow
+}
+
+// enthalpy returns the heat of reaction j (kJ/mol) at T.
+func (m mechanism) enthalpy(j int, T float64) float64 {
+	var ΔH float64
+	for i, h := range m.h {
+		ΔH += m.ν[j][i] * h(T)
+	}
+	return ΔH
+}
+
+// derivatives fills r with the rate of each reaction and dFdW with the
+// production rate of each species (mol/s/kg) for the partial pressures p, and
+// returns the rate at which the reactions absorb heat (per s per kg).
+func (m mechanism) derivatives(T units.Temperature, p, r, dFdW []float64) units.Energy {
+	return m.derivativesAt(T, T, p, r, dFdW)
+}
+
+// derivativesAt is derivatives with the rates evaluated at T and the heats of
+// reaction at Th. The heat absorbed is that of the species produced, which
+// saves finding the heat of each reaction.
+func (m mechanism) derivativesAt(T, Th units.Temperature, p, r, dFdW []float64) units.Energy {
+	m.rates(T.In(units.K), p, r)
+	for i := range dFdW {
+		dFdW[i] = 0
+	}
+	for j, rate := range r {
+		for i, ν := range m.ν[j] {
+			dFdW[i] += ν * rate
+		}
+	}
+	var heats float64
+	for i, h := range m.h {
+		heats += dFdW[i] * h(Th.In(units.K))
+	}
+	return units.Energy(heats) * units.KJ
+}
+
+// validate reports mechanisms whose rate laws do not declare the unit of
+// their partial pressures, or whose reactions do not conserve the elements.
+func (m mechanism) validate() error {
+	if m.pressure <= 0 {
+		return fmt.Errorf("%s mechanism: rate laws do not declare a pressure unit", m.keyName)
+	}
+	for j, r := range m.reactions {
+		for _, element := range thermo.Elements {
+			var balance float64
+			for compound, ν := range r.stoichiometry {
+				balance += ν * thermo.Atoms(compound, element)
+			}
+			if balance != 0 {
+				return fmt.Errorf("%s mechanism: reaction %d does not conserve %s", m.keyName, j+1, element)
+			}
+		}
+	}
+	return nil
+}
+
+// index returns the position of compound in the process gas state, or -1.
+func (m mechanism) index(compound string) int {
+	for i, c := range m.species {
+		if c == compound {
+			return i
+		}
+	}
+	return -1
+}
+
+// equation returns reaction j of m written out, e.g. "CH4 + H2O = CO + 3H2".
+func (m mechanism) equation(j int) string {
+	var reactants, products []string
+	for _, compound := range m.species {
+		ν := m.reactions[j].stoichiometry[compound]
+		term := compound
+		if math.Abs(ν) != 1 {
+			term = fmt.Sprintf("%g%s", math.Abs(ν), compound)
+		}
+		switch {
+		case ν < 0:
+			reactants = append(reactants, term)
+		case ν > 0:
+			products = append(products, term)
+		}
+	}
+	return strings.Join(reactants, " + ") + " = " + strings.Join(products, " + ")
+}
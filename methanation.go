@@ -0,0 +1,26 @@
+package reactor
+
+// dryPPM returns the dry-basis concentration (ppmv) of compound in flows,
+// which are ordered as the species of m.
+func (m mechanism) dryPPM(flows []float64, compound string) float64 {
+	var dry float64
+	for i, c := range m.species {
+		if c != "H2O" {
+			dry += flows[i]
+		}
+	}
+	return flows[m.index(compound)] / dry * 1e6
+}
+
+// dryPPMProfile applies dryPPM at every step of a transposed solver table.
+func (m mechanism) dryPPMProfile(yValues [][]float64, compound string) []float64 {
+	profile := make([]float64, len(yValues[0]))
+	flows := make([]float64, len(m.species))
+	for step := range profile {
+		for i := range flows {
+			flows[i] = yValues[i][step]
+		}
+		profile[step] = m.dryPPM(flows, compound)
+	}
+	return profile
+}
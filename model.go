@@ -0,0 +1,516 @@
+package reactor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/ewancook/reactor/thermo"
+	"github.com/ewancook/reactor/units"
+)
+
+// flueSpecies are the heating gas components, in the order used for sums,
+// flueCP their specific heats and flueCPSlope the slopes of those.
+var (
+	flueSpecies = []string{"N2", "CO2", "H2O", "O2"}
+	flueCP      = specificHeats(flueSpecies)
+	flueCPSlope = specificHeatSlopes(flueSpecies)
+)
+
+// specificHeats returns the specific heat of each of species.
+func specificHeats(species []string) []func(T float64) float64 {
+	cp := make([]func(float64) float64, len(species))
+	for i, compound := range species {
+		cp[i] = thermo.SpecificHeatFunc(compound)
+	}
+	return cp
+}
+
+// specificHeatSlopes returns the slope of the specific heat of each of
+// species with temperature.
+func specificHeatSlopes(species []string) []func(T float64) float64 {
+	slope := make([]func(float64) float64, len(species))
+	for i, compound := range species {
+		slope[i] = thermo.SpecificHeatSlopeFunc(compound)
+	}
+	return slope
+}
+
+// model holds a validated Config along with the quantities derived from it.
+// Its state holds the species flows of one tube (mol/s) followed by T (K),
+// P (kPa) and Tα (K), which is held at Heating.Talpha when the wall
+// temperature or flux is prescribed. The Radial bed repeats the flows and T
+// for each of its rings before P and Tα; rings is one otherwise.
+type model struct {
+	Config
+	mech      mechanism
+	n         int
+	rings     int
+	U         float64
+	area, W   float64
+	ρc        float64
+	flue      []float64 // heating gas flows, ordered as flueSpecies (mol/s)
+	totalFlue float64
+	P0        units.Pressure
+	T0        units.Temperature
+	F0        units.MolarFlow
+
+	// zones holds the model of each of Config.Zones, which start at wStart
+	// (kg) and zStart (m) along the tube; it is nil for a uniform tube.
+	zones          []*model
+	wStart, zStart float64
+
+	// stats counts the work of every integration of the model, shared with
+	// the models derived from it
+	stats *SolverStats
+
+	// partials and rates are reused by every evaluation of the gradients,
+	// and the rest by fillJacobian, which therefore must not run
+	// concurrently on one model
+	partials, rates []float64
+	partialJets     []jet
+	rateJets        []jet
+	dr              [][]float64 // derivatives of the rates with the state
+	dq, perturbed   []float64
+	// transport holds the properties of the process gas for the wall
+	// correlations, and the rest the rings of the radial bed
+	transport                            *thermo.Mixture
+	ring                                 []float64
+	ringFlows, ringHeats, ringCapacities []float64
+	// pellet holds the surface partial pressures of the heterogeneous bed,
+	// and the rest the iteration of filmPartials that finds them, whose
+	// pseudo time step carries from one call to the next within surface
+	pellet, pelletResidual, pelletStep, pelletTrial, pelletRates []float64
+	pelletMatrix                                                 [][]float64
+	pelletΔτ                                                     float64
+}
+
+// newModel validates c and derives its model. A model reuses scratch space
+// in every evaluation of its gradients and Jacobian, so callers must not use
+// one from several goroutines at once: Simulate and each case of
+// SimulateSweep build their own. Profiles hold no model and may be shared.
+func newModel(c Config) (*model, error) {
+	m := &model{Config: c, U: c.Heating.U, stats: &SolverStats{}}
+	var err error
+	if m.mech, err = mechanismFor(c.Mode); err != nil {
+		return nil, err
+	}
+	if err := m.mech.validate(); err != nil {
+		return nil, err
+	}
+	if 1+len(m.mech.species) > jetSize {
+		return nil, fmt.Errorf("reactor: the %s mechanism has %d species, more than the %d partial pressures a jet differentiates", c.Mode, len(m.mech.species), jetSize-1)
+	}
+	for compound := range c.Feed {
+		if m.mech.index(compound) < 0 {
+			return nil, fmt.Errorf("reactor: %s is not a %s species", compound, c.Mode)
+		}
+	}
+	for compound := range c.Flue {
+		if !contains(flueSpecies, compound) {
+			return nil, fmt.Errorf("reactor: %s is not a heating gas species", compound)
+		}
+	}
+	if c.Geometry.D <= 0 || (c.Geometry.Length <= 0 && c.Zones == nil) || c.Geometry.Tubes <= 0 {
+		return nil, fmt.Errorf("reactor: tube diameter, length and number must be positive")
+	}
+	if c.Catalyst.Density <= 0 || c.Catalyst.Voidage <= 0 || c.Catalyst.Voidage >= 1 {
+		return nil, fmt.Errorf("reactor: catalyst density must be positive and voidage between 0 and 1")
+	}
+	if c.Solver.Method != "" && !contains(methods, c.Solver.Method) {
+		return nil, fmt.Errorf("reactor: unknown integration method %q", c.Solver.Method)
+	}
+	if c.Solver.Atol < 0 || c.Solver.Rtol < 0 || c.Solver.InitialStep < 0 || c.Solver.MaxSteps < 0 {
+		return nil, fmt.Errorf("reactor: solver tolerances, initial step and maximum steps must not be negative")
+	}
+	if f := c.Kinetics.Factors; f != nil {
+		if len(f) != len(m.mech.reactions) {
+			return nil, fmt.Errorf("reactor: %d kinetic factors given for %d reactions", len(f), len(m.mech.reactions))
+		}
+		rates := m.mech.rates
+		m.mech.rates = func(T float64, p, r []float64) {
+			rates(T, p, r)
+			for j := range r {
+				r[j] *= f[j]
+			}
+		}
+		rateJets := m.mech.rateJets
+		m.mech.rateJets = func(T jet, p, r []jet) {
+			rateJets(T, p, r)
+			for j := range r {
+				r[j] = r[j].scale(f[j])
+			}
+		}
+	}
+	switch c.Heating.Arrangement {
+	case "", CoCurrent:
+	case CounterCurrent:
+		if c.Mode == Methanation {
+			return nil, fmt.Errorf("reactor: counter-current flow needs a heating gas, not the methanator coolant")
+		}
+	default:
+		return nil, fmt.Errorf("reactor: unknown heating arrangement %q", c.Heating.Arrangement)
+	}
+	if c.Heating.FluxFactor < 0 {
+		return nil, fmt.Errorf("reactor: the flux factor must not be negative")
+	}
+	switch c.Heating.Mode {
+	case "", HeatingGas:
+	case WallTemperature, HeatFlux:
+		if c.Heating.Arrangement == CounterCurrent {
+			return nil, fmt.Errorf("reactor: counter-current flow needs a heating gas, not a prescribed %s", c.Heating.Mode)
+		}
+		if err := c.Heating.Profile.validate(); err != nil {
+			return nil, err
+		}
+	case Radiation:
+		if c.Mode == Methanation {
+			return nil, fmt.Errorf("reactor: radiation needs a heating gas, not the methanator coolant")
+		}
+		f := c.Furnace
+		if f.Pressure <= 0 || f.BeamLength <= 0 || f.TubeEmissivity <= 0 || f.TubeEmissivity > 1 {
+			return nil, fmt.Errorf("reactor: furnace pressure and beam length must be positive and tube emissivity between 0 and 1")
+		}
+		if c.Flue["CO2"]+c.Flue["H2O"] <= 0 {
+			return nil, fmt.Errorf("reactor: radiation needs CO2 or H2O in the heating gas")
+		}
+	default:
+		return nil, fmt.Errorf("reactor: unknown heating mode %q", c.Heating.Mode)
+	}
+	if c.Wall.Thickness < 0 || (c.Wall.Thickness > 0 && c.Wall.Conductivity <= 0) {
+		return nil, fmt.Errorf("reactor: wall thickness must not be negative and a wall needs a positive conductivity")
+	}
+	switch c.Heating.Correlation {
+	case "":
+	case Leva, LiFinlayson, Dixon:
+		if c.Catalyst.Dp <= 0 || c.Wall.Conductivity <= 0 {
+			return nil, fmt.Errorf("reactor: wall correlations need a positive particle diameter and wall conductivity")
+		}
+		if c.Heating.Outside <= 0 && (c.Heating.Mode == "" || c.Heating.Mode == HeatingGas) {
+			return nil, fmt.Errorf("reactor: wall correlations need a positive outside coefficient for a heating gas")
+		}
+	default:
+		return nil, fmt.Errorf("reactor: unknown wall correlation %q", c.Heating.Correlation)
+	}
+	m.rings = 1
+	switch c.Bed {
+	case "", PlugFlow:
+	case Heterogeneous:
+		if c.Gas.Conductivity <= 0 || c.Gas.Viscosity <= 0 || c.Gas.Diffusivity <= 0 || c.Catalyst.Dp <= 0 {
+			return nil, fmt.Errorf("reactor: the heterogeneous bed needs positive gas conductivity, viscosity, diffusivity and particle diameter")
+		}
+	case AxialDispersion:
+		if c.Dispersion.Cells < 2 || c.Gas.Conductivity <= 0 || c.Gas.Viscosity <= 0 || c.Catalyst.Dp <= 0 {
+			return nil, fmt.Errorf("reactor: the dispersion bed needs at least two cells and positive gas conductivity, viscosity and particle diameter")
+		}
+	case Radial2D:
+		r := c.Radial
+		if r.Nodes < 1 || r.Conductivity <= 0 || r.Hw <= 0 {
+			return nil, fmt.Errorf("reactor: the radial bed needs at least one node and positive conductivity and wall coefficient")
+		}
+		m.rings = r.Nodes
+		// the outermost node sees the wall through half a ring of bed
+		resistance := 1/r.Hw + c.Geometry.D/2/float64(r.Nodes)/2/r.Conductivity
+		if c.Heating.Mode != WallTemperature && c.Heating.Mode != Radiation {
+			resistance += 1 / c.Heating.U
+		}
+		m.U = 1 / resistance
+	default:
+		return nil, fmt.Errorf("reactor: unknown bed model %q", c.Bed)
+	}
+	if c.Heating.Adiabatic {
+		m.U = 0
+	}
+	if c.Wall.Thickness > 0 && c.Heating.Correlation == "" && m.rings == 1 && !c.Heating.Adiabatic {
+		// the wall temperatures are found from the fixed U
+		switch c.Heating.Mode {
+		case "", HeatingGas:
+			if 1/c.Heating.U <= m.wallResistance()+m.outsideResistance() {
+				return nil, fmt.Errorf("reactor: U must be below the coefficient of the wall and outside in series")
+			}
+		case HeatFlux:
+			if c.Heating.U <= 0 {
+				return nil, fmt.Errorf("reactor: the wall temperatures under a heat flux need a positive U or a wall correlation")
+			}
+		}
+	}
+	for _, e := range c.Events {
+		if e.Func == nil {
+			return nil, fmt.Errorf("reactor: event %q has no function", e.Name)
+		}
+		if e.Stop && (m.counterCurrent() || c.Bed == AxialDispersion) {
+			return nil, fmt.Errorf("reactor: event %q cannot stop counter-current heating or the dispersion bed", e.Name)
+		}
+	}
+
+	m.n = len(m.mech.species)
+	m.partials, m.rates = make([]float64, m.n), make([]float64, len(m.mech.reactions))
+	m.partialJets, m.rateJets = make([]jet, m.n), make([]jet, len(m.mech.reactions))
+	m.dr = make([][]float64, len(m.mech.reactions))
+	for j := range m.dr {
+		m.dr[j] = make([]float64, m.n+2)
+	}
+	m.dq, m.perturbed = make([]float64, m.n+3), make([]float64, m.n+3)
+	m.transport = thermo.NewMixture(m.mech.species)
+	m.ring = make([]float64, m.n+2)
+	m.ringFlows, m.ringHeats, m.ringCapacities = make([]float64, m.rings), make([]float64, m.rings), make([]float64, m.rings)
+	m.pellet, m.pelletResidual = make([]float64, m.n), make([]float64, m.n)
+	m.pelletStep, m.pelletTrial, m.pelletRates = make([]float64, m.n), make([]float64, m.n), make([]float64, m.n)
+	m.pelletMatrix = make([][]float64, m.n)
+	for i := range m.pelletMatrix {
+		m.pelletMatrix[i] = make([]float64, m.n)
+	}
+	m.area = math.Pi * math.Pow(c.Geometry.D, 2) / 4
+	m.W = c.Catalyst.Density * m.area * c.Geometry.Length
+	m.ρc = c.Catalyst.Density / (1.0 - c.Catalyst.Voidage)
+	m.flue = make([]float64, len(flueSpecies))
+	for i, compound := range flueSpecies {
+		m.flue[i] = c.Flue[compound]
+		m.totalFlue += m.flue[i]
+	}
+	m.P0 = units.Pressure(c.P) * units.KPa
+	m.T0 = units.Temperature(c.T) * units.K
+	for _, flow := range c.Feed {
+		m.F0 += units.MolarFlow(flow) * units.MolPerS
+	}
+	m.F0 /= units.MolarFlow(c.Geometry.Tubes)
+	if c.Zones != nil {
+		if err := m.zoned(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// iP and iTα return the positions of P and Tα in the state.
+func (m *model) iP() int  { return m.rings * (m.n + 1) }
+func (m *model) iTα() int { return m.iP() + 1 }
+
+// initial returns the state at the inlet of a tube.
+func (m *model) initial() []float64 {
+	y := make([]float64, m.iTα()+1)
+	for j := 0; j < m.rings; j++ {
+		k := j * (m.n + 1)
+		for i, compound := range m.mech.species {
+			y[k+i] = m.Feed[compound] / m.Geometry.Tubes * m.fraction(j)
+		}
+		y[k+m.n] = m.T
+	}
+	y[m.iP()], y[m.iTα()] = m.P, m.Heating.Talpha
+	return y
+}
+
+// ODEs evaluates the derivatives of the state with respect to catalyst mass.
+func (m *model) ODEs(f la.Vector, h, x float64, y la.Vector) {
+	if m.zones != nil {
+		m.zone(x).ODEs(f, h, x, y)
+		return
+	}
+	if m.rings > 1 {
+		m.radialODEs(f, h, x, y)
+		return
+	}
+	n := m.n
+	// the state is held in K, kPa and mol/s
+	T := units.Temperature(y[n]) * units.K
+	P := units.Pressure(y[n+1]) * units.KPa
+
+	var totalFlow units.MolarFlow
+	for i := 0; i < n; i++ {
+		totalFlow += units.MolarFlow(y[i]) * units.MolPerS
+	}
+	m.mech.partials(y, m.partials)
+	G := m.mech.massFlow(y) / 1000 / m.area
+	beta := β(m.Catalyst.Voidage, G, m.Catalyst.Dp, m.Gas.Viscosity, m.Gas.Density)
+	alpha := α(beta, m.area, m.ρc, m.Catalyst.Voidage, m.P0)
+
+	var heats units.Energy
+	if m.Bed == Heterogeneous {
+		hv, kv := m.transfer(y)
+		_, heats = m.surface(T, hv, kv, f[:n])
+	} else {
+		heats = m.mech.derivatives(T, m.partials, m.rates, f[:n])
+	}
+	capacity := m.mech.heatCapacity(y[n], y)
+	f[n] = dTdW(m.flux(x, y), m.Geometry.D, m.Catalyst.Density, heats, capacity)
+	f[n+1] = dPdW(alpha, P, m.P0, T, m.T0, totalFlow, m.F0)
+	f[n+2] = m.heatingGradient(x, y)
+}
+
+// heatingGradient returns the heating gas temperature gradient (K/kg) at
+// catalyst mass w and state y.
+func (m *model) heatingGradient(w float64, y []float64) float64 {
+	switch {
+	case m.prescribed():
+		return 0
+	case m.Mode == Methanation:
+		// boiling-water coolant: the shell side stays at Talpha
+		return 0
+	}
+	var aveCP float64
+	for i, cp := range flueCP {
+		aveCP += cp(y[m.iTα()]) * m.flue[i]
+	}
+	aveCP /= m.totalFlue
+
+	gradient := dTαdW(m.flux(w, y), m.Geometry.D, m.Catalyst.Density, m.totalFlue, aveCP) * m.Geometry.Tubes
+	if m.counterCurrent() {
+		return -gradient
+	}
+	return gradient
+}
+
+func (m *model) counterCurrent() bool {
+	return m.Heating.Arrangement == CounterCurrent
+}
+
+// duty returns the heat transferred into the process gas of one tube (kJ/s).
+func (m *model) duty(wValues []float64, yValues [][]float64) float64 {
+	last := len(wValues) - 1
+	if m.Mode == Methanation || m.prescribed() {
+		wall := m.wallFlux(wValues, yValues)
+		for k := range wall {
+			wall[k] *= 4 / m.Geometry.D / m.Catalyst.Density
+		}
+		if m.Bed == AxialDispersion {
+			// each cell exchanges heat at its outlet state
+			var sum float64
+			for k := 1; k < len(wValues); k++ {
+				sum += (wValues[k] - wValues[k-1]) * wall[k]
+			}
+			return sum / 1000
+		}
+		return trapezoid(wValues, wall) / 1000
+	}
+	flows := m.flueFlows()
+	Tin, Tout := yValues[m.iTα()][0], yValues[m.iTα()][last]
+	if m.counterCurrent() {
+		Tin, Tout = Tout, Tin
+	}
+	return enthalpyFlow(flueSpecies, flows, Tin) - enthalpyFlow(flueSpecies, flows, Tout)
+}
+
+// wallFlux returns the heat flux into the process gas (W/m^2) at every step
+// of a transposed solver table.
+func (m *model) wallFlux(wValues []float64, yValues [][]float64) []float64 {
+	flux := make([]float64, len(wValues))
+	y := make([]float64, len(yValues))
+	for k, w := range wValues {
+		for i := range y {
+			y[i] = yValues[i][k]
+		}
+		flux[k] = m.flux(w, y)
+	}
+	return flux
+}
+
+// coordinates returns the position (m) and gas residence time (s) of every step of
+// a transposed solver table of the whole cross section. The residence time
+// integrates the void volume per unit length over the volumetric flow.
+func (m *model) coordinates(wValues []float64, yValues [][]float64) (z, residence []float64) {
+	z = make([]float64, len(wValues))
+	residence = make([]float64, len(wValues))
+	voids := make([]float64, len(wValues)) // s/m
+	for k, w := range wValues {
+		z[k] = m.z(w)
+		var flow float64
+		for i := 0; i < m.n; i++ {
+			flow += yValues[i][k]
+		}
+		volumetric := flow * R * yValues[m.n][k] / (yValues[m.n+1][k] * 1000)
+		voids[k] = m.Catalyst.Voidage * m.area / volumetric
+		if k > 0 {
+			residence[k] = residence[k-1] + (z[k]-z[k-1])*(voids[k]+voids[k-1])/2
+		}
+	}
+	return z, residence
+}
+
+// profile assembles the solver output into a Profile.
+func (m *model) profile(wValues []float64, states [][]float64) *Profile {
+	if m.zones != nil {
+		return m.zonedProfile(wValues, states)
+	}
+	yValues := states
+	if m.rings > 1 {
+		yValues = m.mixed(states)
+	}
+	n, last := m.n, len(wValues)-1
+	p := &Profile{
+		Mode:    m.Mode,
+		Species: m.mech.species,
+		Tubes:   m.Geometry.Tubes,
+		W:       wValues,
+		Flows:   map[string][]float64{},
+		T:       yValues[n],
+		P:       yValues[n+1],
+		Talpha:  yValues[n+2],
+		mech:    m.mech,
+	}
+	for i, compound := range m.mech.species {
+		p.Flows[compound] = yValues[i]
+	}
+	p.Z, p.Residence = m.coordinates(wValues, yValues)
+	if m.Heating.Mode == WallTemperature {
+		p.Talpha = make([]float64, len(wValues))
+		for k, w := range wValues {
+			p.Talpha[k] = m.Heating.Profile.At(m.z(w))
+		}
+	}
+	switch {
+	case m.Bed == Heterogeneous:
+		// the reactions run at the catalyst temperature and surface
+		// composition
+		solid := m.solidProfile(yValues)
+		p.Ts = solid[n]
+		p.Reactions = m.mech.reactionProfiles([][][]float64{solid}, []float64{1})
+	case m.rings > 1:
+		// the reactions run at the state of each ring rather than the
+		// cup-mixed state
+		rings, shares := make([][][]float64, m.rings), make([]float64, m.rings)
+		for j := range rings {
+			k := j * (n + 1)
+			rings[j] = append(append([][]float64(nil), states[k:k+n+1]...), states[m.iP()], states[m.iTα()])
+			shares[j] = m.fraction(j)
+		}
+		p.Reactions = m.mech.reactionProfiles(rings, shares)
+	default:
+		p.Reactions = m.mech.reactionProfiles([][][]float64{yValues}, []float64{1})
+	}
+	p.WallFlux = m.wallFlux(wValues, states)
+	if m.Wall.Thickness > 0 {
+		m.creep(p, wValues, states)
+	}
+	if m.Heating.Correlation != "" {
+		y := make([]float64, len(states))
+		for k := range wValues {
+			for i := range y {
+				y[i] = states[i][k]
+			}
+			p.Resistances = append(p.Resistances, m.resistances(y))
+		}
+	}
+	if m.rings > 1 {
+		for j := 0; j < m.rings; j++ {
+			p.Radius = append(p.Radius, (float64(j)+0.5)*m.width())
+			p.RadialT = append(p.RadialT, states[j*(n+1)+n])
+		}
+	}
+
+	inlet, outlet := make([]float64, n), make([]float64, n)
+	for i := range inlet {
+		inlet[i], outlet[i] = yValues[i][0], yValues[i][last]
+	}
+	p.ElementClosure, p.ClosureElement = m.mech.elementClosure(yValues)
+	p.EnergyClosure = energyClosure(m.mech.species, inlet, outlet, yValues[n][0], yValues[n][last], m.duty(wValues, states))
+	return p
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -1,64 +1,34 @@
-package main
+package reactor
 
 import (
 	"math"
 
-	"github.com/ewancook/reactor/thermo"
+	"github.com/ewancook/reactor/units"
 )
 
-func dFCH4dW(T, denominator float64, partials map[string]float64) float64 {
-	return -reaction1(T, denominator, partials) - reaction3(T, denominator, partials)
+// dTdW returns the process gas temperature gradient (K/kg), where q is the heat
+// flux through the wall (W/m^2), heats is the rate at which the reactions
+// absorb heat per kg of catalyst and capacity is the heat capacity of the
+// process gas flow (W/K).
+func dTdW(q, D, ρb float64, heats units.Energy, capacity float64) float64 {
+	return (q*(4/D)/ρb - heats.In(units.J)) / capacity
 }
 
-func dFH2OdW(T, denominator float64, partials map[string]float64) float64 {
-	return -reaction1(T, denominator, partials) -
-		reaction2(T, denominator, partials) -
-		2*reaction3(T, denominator, partials) - 2*reaction4(T, partials)
+// dPdW returns the pressure gradient (kPa/kg).
+func dPdW(alpha float64, P, P0 units.Pressure, T, T0 units.Temperature, F, F0 units.MolarFlow) float64 {
+	return -alpha / 2 * P0.In(units.KPa) / float64(P/P0) * float64(T/T0) * float64(F/F0)
 }
 
-func dFH2dW(T, denominator float64, partials map[string]float64) float64 {
-	return 3*reaction1(T, denominator, partials) +
-		reaction2(T, denominator, partials) +
-		4*reaction3(T, denominator, partials) + 5*reaction4(T, partials)
-}
-
-func dFCOdW(T, denominator float64, partials map[string]float64) float64 {
-	return reaction1(T, denominator, partials) -
-		reaction2(T, denominator, partials) + 2*reaction4(T, partials)
-}
-
-func dFCO2dW(T, denominator float64, partials map[string]float64) float64 {
-	return reaction2(T, denominator, partials) +
-		reaction3(T, denominator, partials)
-}
-
-func dFC2H6dW(T float64, partials map[string]float64) float64 {
-	return -reaction4(T, partials)
-}
-
-func dTdW(U, D, ρb, Tα, T, reaction_denominator float64, flows, partials map[string]float64) float64 {
-	var denominator float64
-	for compound, flow := range flows {
-		denominator += thermo.SpecificHeat(compound, T) * flow
-	}
-	heats := reaction1(T, reaction_denominator, partials)*reaction1Enthalpy(T) +
-		reaction2(T, reaction_denominator, partials)*reaction2Enthalpy(T) +
-		reaction3(T, reaction_denominator, partials)*reaction3Enthalpy(T) + reaction4(T, partials)*reaction4Enthalpy(T)
-	return (U*(4/D)/ρb*(Tα-T) - heats*1000) / denominator
-}
-
-func dPdW(alpha, P, P0, T, T0, F, F0 float64) float64 {
-	return -alpha / 2 * P0 / (P / P0) * (T / T0) * (F / F0)
-}
-
-func α(beta, area, ρc, ϕ, P0 float64) float64 {
-	return 2 * beta / (area * ρc * (1 - ϕ) * P0 * 1000)
+func α(beta, area, ρc, ϕ float64, P0 units.Pressure) float64 {
+	return 2 * beta / (area * ρc * (1 - ϕ) * P0.In(units.Pa))
 }
 
 func β(ϕ, G, Dp, μ, ρg float64) float64 {
 	return (G * (1 - ϕ) / (ρg * Dp * math.Pow(ϕ, 3))) * (1.75*G + 150*(1-ϕ)*μ/Dp)
 }
 
-func dTαdW(U, D, ρb, T, Tα, mc, aveCP float64) float64 {
-	return U * 4 / D / ρb * (T - Tα) / (mc * aveCP)
+// dTαdW returns the heating gas temperature gradient (K/kg), where q is the
+// heat flux through the wall into the process gas (W/m^2).
+func dTαdW(q, D, ρb, mc, aveCP float64) float64 {
+	return -q * 4 / D / ρb / (mc * aveCP)
 }
@@ -0,0 +1,275 @@
+package reactor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Profile holds the state of one tube at every step of the integration.
+type Profile struct {
+	Mode    Mode
+	Species []string
+	Tubes   float64
+
+	W         []float64            // catalyst mass from the inlet (kg)
+	Z         []float64            // axial position from the inlet (m)
+	Residence []float64            // gas residence time in the voids of the bed from the inlet (s)
+	Flows     map[string][]float64 // species flows of one tube (mol/s)
+	T         []float64            // process gas (cup-mixing) temperature (K)
+	P         []float64            // pressure (kPa)
+	Talpha    []float64            // heating gas, coolant or prescribed wall temperature (K)
+	Ts        []float64            // catalyst temperature of a Heterogeneous bed (K), otherwise nil
+
+	// Radius holds the ring mid-radii (m) of a Radial2D bed and RadialT the
+	// temperature of each ring along the tube (K); both are nil otherwise.
+	Radius  []float64
+	RadialT [][]float64
+
+	Reactions []ReactionProfile
+	WallFlux  []float64 // heat flux through the wall into the process gas (W/m^2)
+
+	// InnerWall and OuterWall hold the temperatures of the tube wall (K),
+	// Stress its hoop stress (MPa) and RuptureLife the time to creep rupture
+	// of HP-modified alloy at that stress and the hotter wall temperature
+	// (h). All are nil when Wall.Thickness is zero. Without a wall
+	// correlation or the radial bed, the wall temperatures follow from the
+	// fixed U: from the heating gas through Heating.Outside and the metal, so
+	// that the bed takes the rest of 1/U, or from the process gas through U
+	// under a HeatFlux.
+	InnerWall   []float64
+	OuterWall   []float64
+	Stress      []float64
+	RuptureLife []float64
+
+	// Resistances breaks down 1/U along the tube when it is found from a
+	// Heating.Correlation, and is nil otherwise.
+	Resistances []Resistances
+
+	// ElementClosure is the largest relative deviation of an element flow
+	// from its inlet value, found for ClosureElement. EnergyClosure is the
+	// imbalance between the enthalpy gained by the process gas and the wall
+	// duty, relative to their scale.
+	ElementClosure float64
+	ClosureElement string
+	EnergyClosure  float64
+
+	// Stats counts the work of the integrator.
+	Stats SolverStats
+
+	// ShootingIterations counts the integrations needed to solve
+	// counter-current heating; it is zero for co-current runs.
+	ShootingIterations int
+
+	// Events lists where the events of the Config occurred, in order along
+	// the tube. The tube ends at an event that stops it.
+	Events []Occurrence
+
+	mech mechanism
+}
+
+// append extends p with the steps of q, a profile of the next zone.
+func (p *Profile) append(q *Profile) {
+	p.W = append(p.W, q.W...)
+	p.Z = append(p.Z, q.Z...)
+	// the residence time of q starts from its own inlet
+	t := p.Residence[len(p.Residence)-1]
+	for _, residence := range q.Residence {
+		p.Residence = append(p.Residence, t+residence)
+	}
+	for compound := range p.Flows {
+		p.Flows[compound] = append(p.Flows[compound], q.Flows[compound]...)
+	}
+	p.T = append(p.T, q.T...)
+	p.P = append(p.P, q.P...)
+	p.Talpha = append(p.Talpha, q.Talpha...)
+	p.Ts = append(p.Ts, q.Ts...)
+	for j := range p.RadialT {
+		p.RadialT[j] = append(p.RadialT[j], q.RadialT[j]...)
+	}
+	for j := range p.Reactions {
+		r := &p.Reactions[j]
+		r.Rate = append(r.Rate, q.Reactions[j].Rate...)
+		r.Enthalpy = append(r.Enthalpy, q.Reactions[j].Enthalpy...)
+		r.Heat = append(r.Heat, q.Reactions[j].Heat...)
+	}
+	p.WallFlux = append(p.WallFlux, q.WallFlux...)
+	p.InnerWall = append(p.InnerWall, q.InnerWall...)
+	p.OuterWall = append(p.OuterWall, q.OuterWall...)
+	p.Stress = append(p.Stress, q.Stress...)
+	p.RuptureLife = append(p.RuptureLife, q.RuptureLife...)
+	p.Resistances = append(p.Resistances, q.Resistances...)
+}
+
+// MaxTMT returns the highest tube metal temperature (K) and its step, or zero
+// and -1 without wall temperatures.
+func (p *Profile) MaxTMT() (float64, int) {
+	max, step := 0.0, -1
+	for k := range p.InnerWall {
+		if T := math.Max(p.InnerWall[k], p.OuterWall[k]); T > max {
+			max, step = T, k
+		}
+	}
+	return max, step
+}
+
+// RemainingLife returns the creep life (h) left at the weakest step of a tube
+// that has already served the given hours at its present conditions, by
+// Robinson's life fraction rule, and that step. It is +Inf and -1 without
+// rupture lives.
+func (p *Profile) RemainingLife(service float64) (float64, int) {
+	life, step := math.Inf(1), -1
+	for k, tr := range p.RuptureLife {
+		if tr < life {
+			life, step = tr, k
+		}
+	}
+	return life - service, step
+}
+
+// ReactionProfile holds the rate and heat of one reaction along the tube.
+type ReactionProfile struct {
+	Equation string
+	Rate     []float64 // mol/s/kg
+	Enthalpy []float64 // heat of reaction (kJ/mol)
+	Heat     []float64 // heat absorbed (W/kg)
+}
+
+// Outlet returns the total outlet flow of compound from all tubes (mol/s).
+func (p *Profile) Outlet(compound string) float64 {
+	flows := p.Flows[compound]
+	return flows[len(flows)-1] * p.Tubes
+}
+
+// KeyReactant returns the name of the reactant whose conversion is reported.
+func (p *Profile) KeyReactant() string {
+	return p.mech.keyName
+}
+
+// Conversion returns the conversion of the key reactant along the tube.
+func (p *Profile) Conversion() []float64 {
+	return conversion(p.Flows[p.mech.key])
+}
+
+// DryPPM returns the dry-basis concentration of compound along the tube (ppmv).
+func (p *Profile) DryPPM(compound string) []float64 {
+	return p.mech.dryPPMProfile(p.table(), compound)
+}
+
+func conversion(flows []float64) []float64 {
+	conversions := make([]float64, len(flows))
+	for k, v := range flows {
+		conversions[k] = 1 - v/flows[0]
+	}
+	return conversions
+}
+
+// table returns the species flows, T, P and Tα as a transposed solver table.
+func (p *Profile) table() [][]float64 {
+	var yValues [][]float64
+	for _, compound := range p.Species {
+		yValues = append(yValues, p.Flows[compound])
+	}
+	return append(yValues, p.T, p.P, p.Talpha)
+}
+
+// reactionProfiles evaluates the reactions of m at every step of the
+// transposed solver tables of the rings of a bed, each weighted by its share
+// of the catalyst. A plug flow bed is a single ring with a share of one.
+// Enthalpy is likewise the weighted mean of the heats of reaction at the ring
+// temperatures.
+func (m mechanism) reactionProfiles(rings [][][]float64, shares []float64) []ReactionProfile {
+	n, steps := len(m.species), len(rings[0][0])
+	reactions := make([]ReactionProfile, len(m.reactions))
+	for j := range reactions {
+		reactions[j] = ReactionProfile{
+			Equation: m.equation(j),
+			Rate:     make([]float64, steps),
+			Enthalpy: make([]float64, steps),
+			Heat:     make([]float64, steps),
+		}
+	}
+	y := make([]float64, len(rings[0]))
+	partials, rates := make([]float64, n), make([]float64, len(m.reactions))
+	for step := 0; step < steps; step++ {
+		for k, yValues := range rings {
+			for i := range y {
+				y[i] = yValues[i][step]
+			}
+			m.partials(y, partials)
+			m.rates(y[n], partials, rates)
+			for j, rate := range rates {
+				r := &reactions[j]
+				ΔH := m.enthalpy(j, y[n])
+				r.Rate[step] += shares[k] * rate
+				r.Enthalpy[step] += shares[k] * ΔH
+				r.Heat[step] += shares[k] * rate * ΔH * 1000
+			}
+		}
+	}
+	return reactions
+}
+
+// WriteCSV writes the profile to w, one row per step.
+func (p *Profile) WriteCSV(w io.Writer) error {
+	header := []string{"W (kg)", "z (m)", "t (s)"}
+	for _, compound := range p.Species {
+		header = append(header, compound+" (mol/s)")
+	}
+	header = append(header, "T (K)", "P (kPa)", "Talpha (K)")
+	for j, r := range p.Reactions {
+		name := fmt.Sprintf("r%d %s", j+1, r.Equation)
+		header = append(header, name+" (mol/s/kg)", name+" dH (kJ/mol)", name+" heat (W/kg)")
+	}
+	header = append(header, "wall flux (W/m^2)")
+	if p.Ts != nil {
+		header = append(header, "Ts (K)")
+	}
+	if p.RuptureLife != nil {
+		header = append(header, "inner wall (K)", "outer wall (K)", "hoop stress (MPa)", "rupture life (h)")
+	}
+	if p.Resistances != nil {
+		header = append(header, "U (W/m^2K)", "bed resistance (m^2K/W)", "wall resistance (m^2K/W)", "outside resistance (m^2K/W)")
+	}
+	for _, r := range p.Radius {
+		header = append(header, fmt.Sprintf("T r=%.4g m (K)", r))
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', 8, 64)
+	}
+	for step := range p.W {
+		row := []string{format(p.W[step]), format(p.Z[step]), format(p.Residence[step])}
+		for _, series := range p.table() {
+			row = append(row, format(series[step]))
+		}
+		for _, r := range p.Reactions {
+			row = append(row, format(r.Rate[step]), format(r.Enthalpy[step]), format(r.Heat[step]))
+		}
+		row = append(row, format(p.WallFlux[step]))
+		if p.Ts != nil {
+			row = append(row, format(p.Ts[step]))
+		}
+		if p.RuptureLife != nil {
+			row = append(row, format(p.InnerWall[step]), format(p.OuterWall[step]), format(p.Stress[step]), format(p.RuptureLife[step]))
+		}
+		if p.Resistances != nil {
+			r := p.Resistances[step]
+			row = append(row, format(1/r.Total()), format(r.Bed), format(r.Wall), format(r.Outside))
+		}
+		for _, T := range p.RadialT {
+			row = append(row, format(T[step]))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
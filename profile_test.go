@@ -0,0 +1,92 @@
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"math"
+	"testing"
+)
+
+func TestEquation(t *testing.T) {
+	results := map[string]string{
+		steamReforming.equation(0):  "CH4 + H2O = CO + 3H2",
+		steamReforming.equation(3):  "2H2O + C2H6 = 2CO + 5H2",
+		ammoniaCracking.equation(0): "2NH3 = N2 + 3H2",
+	}
+	for res, expected := range results {
+		if res != expected {
+			t.Errorf("incorrect equation: expected %q; got %q", expected, res)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	c := DefaultConfig()
+	c.Mode, c.Feed = Cracking, map[string]float64{"NH3": 100}
+	m, err := newModel(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wValues := []float64{0, 1}
+	yValues := [][]float64{{0.5, 0.4}, {0.01, 0.16}, {0, 0.05}, {800, 790}, {2000, 1999}, {1200, 1190}}
+	p := m.profile(wValues, yValues)
+	if p.WallFlux[0] != 40*400 {
+		t.Errorf("incorrect wall flux: expected %f; got %f", 40*400.0, p.WallFlux[0])
+	}
+	var buffer bytes.Buffer
+	if err := p.WriteCSV(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the position, residence time and the default wall's temperatures, stress
+	// and rupture life follow W
+	if len(records) != 3 || len(records[0]) != 17 {
+		t.Errorf("expected 3 rows of 17 columns; got %d rows of %d", len(records), len(records[0]))
+	}
+}
+
+func TestCoordinates(t *testing.T) {
+	m, err := newModel(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a gas that neither reacts nor heats along the whole tube
+	flows := []float64{0.3, 0.1, 0, 0.01, 0.5, 0.09}
+	yValues := make([][]float64, len(flows)+3)
+	for i, flow := range flows {
+		yValues[i] = []float64{flow, flow}
+	}
+	yValues[m.n], yValues[m.n+1], yValues[m.n+2] = []float64{1000, 1000}, []float64{2000, 2000}, []float64{1500, 1500}
+	z, residence := m.coordinates([]float64{0, m.W}, yValues)
+	if math.Abs(z[1]-15) > 1e-9 {
+		t.Errorf("expected the outlet at 15 m; got %f", z[1])
+	}
+	volumetric := 1.0 * R * 1000 / 2e6
+	expected := 0.44 * math.Pi * 0.11 * 0.11 / 4 * 15 / volumetric
+	if math.Abs(residence[1]-expected) > 1e-9*expected {
+		t.Errorf("expected a residence time of %f s; got %f", expected, residence[1])
+	}
+
+	// the coordinates carry on across zones of different packing
+	c := DefaultConfig()
+	c.Zones = []Zone{{Length: 2, Catalyst: Catalyst{Density: 1200}}, {Length: 13, D: 0.1}}
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 1; k < len(p.W); k++ {
+		if p.Z[k] < p.Z[k-1] || p.Residence[k] < p.Residence[k-1] {
+			t.Fatalf("step %d: expected the coordinates to increase; got %f m and %f s", k, p.Z[k], p.Residence[k])
+		}
+		if p.W[k] == p.W[k-1] && math.Abs(p.Z[k]-2) > 1e-9 {
+			t.Errorf("expected the zone boundary at 2 m; got %f", p.Z[k])
+		}
+	}
+	if last := len(p.Z) - 1; math.Abs(p.Z[last]-15) > 1e-9 {
+		t.Errorf("expected the outlet at 15 m; got %f", p.Z[last])
+	}
+}
@@ -0,0 +1,157 @@
+package reactor
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/ewancook/reactor/thermo"
+	"github.com/ewancook/reactor/units"
+)
+
+// radialPeclet is the radial Péclet number for mass, u·dp/Der, of a packed
+// bed at high Reynolds number (Fahien and Smith).
+const radialPeclet = 10
+
+// The two-dimensional model divides the tube into Radial.Nodes rings of equal
+// width by the method of lines. Ring j holds the catalyst and flow in the
+// fraction (2j+1)/N^2 of the cross section, with its node at the mid-radius,
+// and exchanges heat by conduction (λer) and species by dispersion with its
+// neighbours. The outermost ring sees the wall through its half-width, the
+// bed-side wall coefficient and, for a heating gas, Heating.U in series.
+
+// width returns the width of a ring (m).
+func (m *model) width() float64 {
+	return m.Geometry.D / 2 / float64(m.rings)
+}
+
+// fraction returns the fraction of the cross section occupied by ring j.
+func (m *model) fraction(j int) float64 {
+	return float64(2*j+1) / float64(m.rings*m.rings)
+}
+
+// radialODEs evaluates the derivatives of the two-dimensional state, which
+// holds the species flows (mol/s) and T (K) of each ring followed by P (kPa)
+// and Tα (K), with respect to the catalyst mass of the whole tube.
+func (m *model) radialODEs(f la.Vector, h, x float64, y la.Vector) {
+	n, N := m.n, m.rings
+	iP := m.iP()
+	P := units.Pressure(y[iP]) * units.KPa
+	perMass := 1 / (m.Catalyst.Density * m.area)
+	Δr := m.width()
+
+	var totalFlow units.MolarFlow
+	var massFlow, meanT float64
+	ring, ringFlows, capacities := m.ring, m.ringFlows, m.ringCapacities
+	heats := m.ringHeats // heat gained by each ring (W/kg)
+	for j := 0; j < N; j++ {
+		k := j * (n + 1)
+		copy(ring, y[k:k+n+1])
+		ring[n+1] = y[iP]
+		T := units.Temperature(y[k+n]) * units.K
+		ringFlows[j] = 0
+		for i := 0; i < n; i++ {
+			ringFlows[j] += y[k+i]
+		}
+		massFlow += m.mech.massFlow(ring)
+		capacities[j] = m.mech.heatCapacity(y[k+n], ring)
+		totalFlow += units.MolarFlow(ringFlows[j]) * units.MolPerS
+		meanT += y[k+n] * ringFlows[j]
+
+		m.mech.partials(ring, m.partials)
+		absorbed := m.mech.derivatives(T, m.partials, m.rates, f[k:k+n])
+		for i := 0; i < n; i++ {
+			f[k+i] *= m.fraction(j)
+		}
+		heats[j] = -absorbed.In(units.J) * m.fraction(j)
+	}
+	meanT /= totalFlow.In(units.MolPerS)
+
+	for j := 0; j+1 < N; j++ {
+		k, l := j*(n+1), (j+1)*(n+1)
+		face := 2 * math.Pi * float64(j+1) * Δr
+		conduction := m.Radial.Conductivity * face * (y[k+n] - y[l+n]) / Δr * perMass
+		heats[j] -= conduction
+		heats[j+1] += conduction
+
+		// molar flux density (mol/m^2s) at the face, so that Der·c = dp·g/Pe
+		g := (ringFlows[j]/m.fraction(j) + ringFlows[j+1]/m.fraction(j+1)) / 2 / m.area
+		dispersion := m.Catalyst.Dp * g / radialPeclet * face / Δr * perMass
+		for i, h := range m.mech.h {
+			transfer := dispersion * (y[k+i]/ringFlows[j] - y[l+i]/ringFlows[j+1])
+			f[k+i] -= transfer
+			f[l+i] += transfer
+			// species arrive at the temperature of the ring they left
+			ΔH := (h(y[k+n]) - h(y[l+n])) * 1000
+			if transfer > 0 {
+				heats[j+1] += transfer * ΔH
+			} else {
+				heats[j] += transfer * ΔH
+			}
+		}
+	}
+	heats[N-1] += m.flux(x, y) * math.Pi * m.Geometry.D * perMass
+
+	for j := 0; j < N; j++ {
+		f[j*(n+1)+n] = heats[j] / capacities[j]
+	}
+
+	G := massFlow / 1000 / m.area
+	beta := β(m.Catalyst.Voidage, G, m.Catalyst.Dp, m.Gas.Viscosity, m.Gas.Density)
+	alpha := α(beta, m.area, m.ρc, m.Catalyst.Voidage, m.P0)
+	f[iP] = dPdW(alpha, P, m.P0, units.Temperature(meanT)*units.K, m.T0, totalFlow, m.F0)
+	f[iP+1] = m.heatingGradient(x, y)
+}
+
+// mixed reduces a transposed two-dimensional solver table to the species
+// flows, cup-mixing temperature, P and Tα of the whole tube.
+func (m *model) mixed(yValues [][]float64) [][]float64 {
+	n, N, steps := m.n, m.rings, len(yValues[0])
+	table := make([][]float64, n+3)
+	for i := range table {
+		table[i] = make([]float64, steps)
+	}
+	rings := make([][]float64, N)
+	temperatures := make([]float64, N)
+	for step := 0; step < steps; step++ {
+		for j := range rings {
+			rings[j] = make([]float64, n)
+			for i := range rings[j] {
+				rings[j][i] = yValues[j*(n+1)+i][step]
+				table[i][step] += rings[j][i]
+			}
+			temperatures[j] = yValues[j*(n+1)+n][step]
+		}
+		table[n][step] = cupMixing(m.mech.species, rings, temperatures)
+		table[n+1][step] = yValues[m.iP()][step]
+		table[n+2][step] = yValues[m.iTα()][step]
+	}
+	return table
+}
+
+// cupMixing returns the temperature (K) at which the combined flows of the
+// rings carry the same enthalpy as the rings at their own temperatures.
+func cupMixing(species []string, rings [][]float64, temperatures []float64) float64 {
+	total := make([]float64, len(species))
+	var H, T, flow float64
+	for j, flows := range rings {
+		H += enthalpyFlow(species, flows, temperatures[j])
+		for i, f := range flows {
+			total[i] += f
+			T += f * temperatures[j]
+			flow += f
+		}
+	}
+	T /= flow
+	for iteration := 0; iteration < 50; iteration++ {
+		var capacity float64
+		for i, compound := range species {
+			capacity += thermo.SpecificHeat(compound, T) * total[i] / 1000
+		}
+		ΔT := (enthalpyFlow(species, total, T) - H) / capacity
+		T -= ΔT
+		if math.Abs(ΔT) < 1e-9 {
+			break
+		}
+	}
+	return T
+}
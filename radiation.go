@@ -0,0 +1,90 @@
+package reactor
+
+import (
+	"math"
+)
+
+// σ is the Stefan–Boltzmann constant (W/m^2K^4).
+const σ = 5.670374e-8
+
+// wsgg holds the weighted-sum-of-grey-gases coefficients of Smith, Shen and
+// Friedman (1982) for one ratio of H2O to CO2 partial pressure: the absorption
+// coefficient of each grey gas (per atm m) and the polynomial in T (K) of its
+// weight.
+type wsgg struct {
+	ratio float64
+	κ     [3]float64
+	b     [3][4]float64
+}
+
+var wsggCoefficients = []wsgg{
+	{
+		ratio: 1,
+		κ:     [3]float64{0.4303, 7.055, 178.1},
+		b: [3][4]float64{
+			{5.150e-1, -2.303e-4, 0.9779e-7, -1.494e-11},
+			{0.7749e-1, 3.399e-4, -2.297e-7, 3.770e-11},
+			{1.907e-1, -1.824e-4, 0.5608e-7, -0.5122e-11},
+		},
+	},
+	{
+		ratio: 2,
+		κ:     [3]float64{0.4201, 6.516, 131.9},
+		b: [3][4]float64{
+			{6.508e-1, -5.551e-4, 3.029e-7, -5.353e-11},
+			{-0.2504e-1, 6.112e-4, -3.882e-7, 6.528e-11},
+			{2.718e-1, -3.118e-4, 1.221e-7, -1.612e-11},
+		},
+	},
+}
+
+// gasEmissivity returns the total emissivity of a mixture containing H2O and
+// CO2 at partial pressures pH2O and pCO2 (atm) and temperature T (K), over a
+// mean beam length L (m). The coefficients are those for the nearest ratio of
+// pH2O to pCO2 and hold between 600 K and 2400 K.
+func gasEmissivity(pH2O, pCO2, T, L float64) float64 {
+	c := wsggCoefficients[0]
+	if pCO2 > 0 && pH2O/pCO2 > 1.5 {
+		c = wsggCoefficients[1]
+	}
+	var ε float64
+	for i := range c.κ {
+		a := c.b[i][0] + c.b[i][1]*T + c.b[i][2]*T*T + c.b[i][3]*T*T*T
+		ε += a * (1 - math.Exp(-c.κ[i]*(pH2O+pCO2)*L))
+	}
+	return ε
+}
+
+// exchange returns the factor by which σ(Tα^4 - Tw^4) is multiplied to give
+// the radiant flux from the heating gas at Tα (K) to the tube wall, treating
+// the gas and the tubes it surrounds as grey, scaled by the flux factor.
+func (m *model) exchange(Tα float64) float64 {
+	atm := m.Furnace.Pressure / 101.325
+	pH2O := atm * m.Flue["H2O"] / m.totalFlue
+	pCO2 := atm * m.Flue["CO2"] / m.totalFlue
+	εg := gasEmissivity(pH2O, pCO2, Tα, m.Furnace.BeamLength)
+	return m.fluxFactor() / (1/εg + 1/m.Furnace.TubeEmissivity - 1)
+}
+
+// radiantFlux returns the heat flux into the process gas (W/m^2) and the
+// tube wall temperature (K) that balance radiation from the heating gas at Tα
+// against transfer to the process gas at T with coefficient U.
+func (m *model) radiantFlux(Tα, T, U float64) (q, Tw float64) {
+	F := m.exchange(Tα)
+	balance := func(Tw float64) float64 {
+		return F*σ*(math.Pow(Tα, 4)-math.Pow(Tw, 4)) - U*(Tw-T)
+	}
+	// the balance falls monotonically with Tw, so bisect between the two
+	// temperatures
+	lo, hi := math.Min(T, Tα), math.Max(T, Tα)
+	for i := 0; i < 100 && hi-lo > 1e-9; i++ {
+		Tw = (lo + hi) / 2
+		if balance(Tw) > 0 {
+			lo = Tw
+		} else {
+			hi = Tw
+		}
+	}
+	Tw = (lo + hi) / 2
+	return U * (Tw - T), Tw
+}
@@ -0,0 +1,50 @@
+package reactor
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestGasEmissivity(t *testing.T) {
+	previous := 0.0
+	for _, L := range []float64{0.5, 1, 3, 10} {
+		ε := gasEmissivity(0.1, 0.1, 1200, L)
+		if ε <= previous || ε >= 1 {
+			t.Errorf("L %g: expected an emissivity rising with path length below 1; got %f after %f", L, ε, previous)
+		}
+		previous = ε
+	}
+	if hot, cold := gasEmissivity(0.1, 0.1, 1500, 1), gasEmissivity(0.1, 0.1, 1000, 1); hot >= cold {
+		t.Errorf("expected the emissivity to fall with temperature; got %f at 1500 K and %f at 1000 K", hot, cold)
+	}
+	if ε := gasEmissivity(0, 0, 1200, 3); ε != 0 {
+		t.Errorf("expected a transparent gas without CO2 and H2O; got %f", ε)
+	}
+}
+
+func TestSimulateRadiation(t *testing.T) {
+	c := DefaultConfig()
+	c.Heating.Mode = Radiation
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := newModel(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := range p.W {
+		q, Tw := m.radiantFlux(p.Talpha[k], p.T[k], m.U)
+		if Tw < p.T[k] || Tw > p.Talpha[k] {
+			t.Fatalf("step %d: expected the wall between %f and %f; got %f", k, p.T[k], p.Talpha[k], Tw)
+		}
+		radiated := σ * m.exchange(p.Talpha[k]) * (math.Pow(p.Talpha[k], 4) - math.Pow(Tw, 4))
+		if math.Abs(radiated-q) > 1e-6*q || math.Abs(p.WallFlux[k]-q) > 1e-6*q {
+			t.Fatalf("step %d: radiated %f, conducted %f and reported %f W/m^2", k, radiated, q, p.WallFlux[k])
+		}
+	}
+	if p.ElementClosure > 1e-9 || p.EnergyClosure > 1e-3 {
+		t.Errorf("poor closure: element %e; energy %e", p.ElementClosure, p.EnergyClosure)
+	}
+}
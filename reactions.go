@@ -1,43 +1,142 @@
-package main
+package reactor
 
 import (
 	. "math"
+)
+
+// The rate laws below take partial pressures as plain float64 in bar, the
+// unit of the adsorption and equilibrium constants in kinetics.go. They are
+// not typed: mechanisms declare the unit, and partials converts the typed
+// process gas pressure into it before the rates are called.
+
+// minPartial (bar) bounds the partial pressures that appear in rate
+// denominators. Methanator feeds can be bone dry and cracker feeds are pure
+// ammonia, where the driving forces would otherwise divide by zero.
+const minPartial = 1e-6
+
+// minHydrogenPartial (bar) regularises the hydrogen partial pressure, which
+// every rate law here divides by. Feeds of pure methane and steam, or pure
+// ammonia, start without hydrogen, where the Xu–Froment rates are 0/0 and the
+// Temkin rate is unbounded. Below the bound rates are evaluated as if the
+// hydrogen partial pressure were at the bound, so the profile is only
+// physically meaningful once hydrogen has risen above it.
+const minHydrogenPartial = 1e-4
+
+// Positions of the species of steamReforming in the partial pressures passed
+// to its rate laws.
+const (
+	sCO = iota
+	sH2
+	sCH4
+	sCO2
+	sH2O
+	sC2H6
+)
 
-	. "github.com/ewancook/reactor/thermo"
+// Positions of the species of ammoniaCracking in the partial pressures passed
+// to its rate law.
+const (
+	aNH3 = iota
+	aN2
+	aH2
 )
 
-func reaction1Enthalpy(T float64) float64 {
-	return Enthalpy("CO", T) + Enthalpy("H2", T)*3 - Enthalpy("H2O", T) - Enthalpy("CH4", T)
+func bounded(p float64) float64 {
+	return Max(p, minPartial)
+}
+
+func hydrogen(pH2 float64) float64 {
+	return Max(pH2, minHydrogenPartial)
+}
+
+func _denominator(T float64, p []float64) float64 {
+	pH2 := hydrogen(p[sH2])
+	d := 1 + kCO(T)*p[sCO] + kH2(T)*Sqrt(pH2) + kH2O(T)*p[sH2O]/pH2
+	return d * d
+}
+
+// The driving forces below are multiplied through by the reactant partial
+// pressures so that they stay finite as CO, CO2 or CH4 tend to zero, which is
+// the normal state of affairs at a methanator inlet or outlet.
+
+func reaction1(T, denominator float64, p []float64) float64 {
+	pH2O, pH2 := bounded(p[sH2O]), hydrogen(p[sH2])
+	return k1(T) / Pow(pH2, 1.25) * (p[sCH4]*Sqrt(pH2O) - p[sCO]*pH2*pH2*pH2/kp1(T)/Sqrt(pH2O)) / denominator
+}
+
+func reaction2(T, denominator float64, p []float64) float64 {
+	pH2O, pH2 := bounded(p[sH2O]), hydrogen(p[sH2])
+	return k2(T) / Sqrt(pH2) * (p[sCO]*Sqrt(pH2O) - p[sCO2]*pH2/kp2(T)/Sqrt(pH2O)) / denominator
+}
+
+func reaction3(T, denominator float64, p []float64) float64 {
+	pH2O, pH2 := bounded(p[sH2O]), hydrogen(p[sH2])
+	return k3(T) / Pow(pH2, 1.75) * (p[sCH4]*pH2O - p[sCO2]*(pH2*pH2)*(pH2*pH2)/kp3(T)/pH2O) / denominator
 }
 
-func reaction2Enthalpy(T float64) float64 {
-	return Enthalpy("H2", T) + Enthalpy("CO2", T) - Enthalpy("CO", T) - Enthalpy("H2O", T)
+func reaction4(T float64, p []float64) float64 {
+	pH2O, pH2 := bounded(p[sH2O]), hydrogen(p[sH2])
+	d := 1 + 25.2*p[sC2H6]*pH2/pH2O + 0.077*pH2O/pH2
+	return (k4(T) * p[sC2H6]) / (d * d) / 3.6
+}
+
+// reaction5 is a Temkin–Pyzhev rate for ammonia decomposition,
+// 2NH3 = N2 + 3H2.
+func reaction5(T float64, p []float64) float64 {
+	pNH3, pN2, pH2 := bounded(p[aNH3]), p[aN2], hydrogen(p[aH2])
+	pH2Cubed := Sqrt(pH2 * pH2 * pH2)
+	return k5(T) * (pNH3/pH2Cubed - pN2*pH2Cubed/kp5(T)/pNH3)
+}
+
+// The jet forms of the rate laws below carry the derivatives of each rate with
+// respect to T and the partial pressures, for the analytical Jacobian. They
+// must follow the rate laws above term by term.
+
+func boundedJet(p jet) jet {
+	return p.atLeast(minPartial)
 }
 
-func reaction3Enthalpy(T float64) float64 {
-	return Enthalpy("CO2", T) + Enthalpy("H2", T)*4 - Enthalpy("H2O", T)*2 - Enthalpy("CH4", T)
+func hydrogenJet(pH2 jet) jet {
+	return pH2.atLeast(minHydrogenPartial)
 }
 
-func reaction4Enthalpy(T float64) float64 {
-	return Enthalpy("CO", T)*2 + Enthalpy("H2", T)*5 - Enthalpy("C2H6", T) - Enthalpy("H2O", T)*2
+func denominatorJet(T jet, p []jet) jet {
+	pH2 := hydrogenJet(p[sH2])
+	sum := arrhenius(kCO, eCO, T).mul(p[sCO]).
+		add(arrhenius(kH2, eH2, T).mul(pH2.pow(0.5))).
+		add(arrhenius(kH2O, eH2O, T).mul(p[sH2O]).div(pH2))
+	return sum.plus(1).pow(2)
 }
 
-func _denominator(T float64, partials map[string]float64) float64 {
-	return Pow(1+kCO(T)*partials["CO"]+kH2(T)*Pow(partials["H2"], 0.5)+kH2O(T)*partials["H2O"]/partials["H2"], 2)
+func reaction1Jet(T, denominator jet, p []jet) jet {
+	pH2O, pH2 := boundedJet(p[sH2O]), hydrogenJet(p[sH2])
+	forward := p[sCH4].mul(pH2O.pow(0.5))
+	reverse := p[sCO].mul(pH2.pow(3)).div(vantHoff(kp1, θ1, T)).div(pH2O.pow(0.5))
+	return arrhenius(k1, e1, T).div(pH2.pow(1.25)).mul(forward.sub(reverse)).div(denominator)
 }
 
-func reaction1(T, denominator float64, partials map[string]float64) float64 {
-	return (k1(T) * partials["CH4"] * Pow(partials["H2O"], 0.5) / Pow(partials["H2"], 1.25)) * (1 - partials["CO"]*Pow(partials["H2"], 3)/kp1(T)/partials["CH4"]/partials["H2O"]) / denominator
+func reaction2Jet(T, denominator jet, p []jet) jet {
+	pH2O, pH2 := boundedJet(p[sH2O]), hydrogenJet(p[sH2])
+	forward := p[sCO].mul(pH2O.pow(0.5))
+	reverse := p[sCO2].mul(pH2).div(vantHoff(kp2, θ2, T)).div(pH2O.pow(0.5))
+	return arrhenius(k2, e2, T).div(pH2.pow(0.5)).mul(forward.sub(reverse)).div(denominator)
 }
 
-func reaction2(T, denominator float64, partials map[string]float64) float64 {
-	return (k2(T) * partials["CO"] * Pow(partials["H2O"], 0.5) / Pow(partials["H2"], 0.5)) * (1 - partials["CO2"]*partials["H2"]/kp2(T)/partials["CO"]/partials["H2O"]) / denominator
+func reaction3Jet(T, denominator jet, p []jet) jet {
+	pH2O, pH2 := boundedJet(p[sH2O]), hydrogenJet(p[sH2])
+	forward := p[sCH4].mul(pH2O)
+	reverse := p[sCO2].mul(pH2.pow(4)).div(vantHoff(kp3, θ3, T)).div(pH2O)
+	return arrhenius(k3, e3, T).div(pH2.pow(1.75)).mul(forward.sub(reverse)).div(denominator)
 }
 
-func reaction3(T, denominator float64, partials map[string]float64) float64 {
-	return (k3(T) * partials["CH4"] * partials["H2O"] / Pow(partials["H2"], 1.75)) * (1 - partials["CO2"]*Pow(partials["H2"], 4)/kp3(T)/partials["CH4"]/Pow(partials["H2O"], 2)) / denominator
+func reaction4Jet(T jet, p []jet) jet {
+	pH2O, pH2 := boundedJet(p[sH2O]), hydrogenJet(p[sH2])
+	adsorption := p[sC2H6].mul(pH2).div(pH2O).scale(25.2).add(pH2O.div(pH2).scale(0.077)).plus(1)
+	return arrhenius(k4, e4, T).mul(p[sC2H6]).div(adsorption.pow(2)).scale(1 / 3.6)
 }
 
-func reaction4(T float64, partials map[string]float64) float64 {
-	return (k4(T) * partials["C2H6"]) / Pow(1+25.2*partials["C2H6"]/100*partials["H2"]/partials["H2O"]+0.077*partials["H2O"]/partials["H2"], 2) / 3.6
+func reaction5Jet(T jet, p []jet) jet {
+	pNH3, pN2, pH2 := boundedJet(p[aNH3]), p[aN2], hydrogenJet(p[aH2])
+	reverse := pN2.mul(pH2.pow(1.5)).div(kp5Jet(T)).div(pNH3)
+	return arrhenius(k5, e5, T).mul(pNH3.div(pH2.pow(1.5)).sub(reverse))
 }
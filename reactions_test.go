@@ -1,29 +1,92 @@
-package main
+package reactor
 
 import (
 	"math"
 	"testing"
+
+	"github.com/ewancook/reactor/units"
 )
 
 const tolerance = 0.01
 
 func TestReaction1Enthalpy(t *testing.T) {
-	res, expected := reaction1Enthalpy(298.15), 206.20
+	res, expected := steamReforming.enthalpy(0, 298.15), 206.20
 	if math.Abs(res-expected) >= tolerance {
 		t.Errorf("incorrect reaction enthalpy: expected %f; got %f", expected, res)
 	}
 }
 
 func TestReaction2Enthalpy(t *testing.T) {
-	res, expected := reaction2Enthalpy(298.15), -41.20
+	res, expected := steamReforming.enthalpy(1, 298.15), -41.20
 	if math.Abs(res-expected) >= tolerance {
 		t.Errorf("incorrect reaction enthalpy: expected %f; got %f", expected, res)
 	}
 }
 
 func TestReaction3Enthalpy(t *testing.T) {
-	res, expected := reaction3Enthalpy(298.15), 165.00
+	res, expected := steamReforming.enthalpy(2, 298.15), 165.00
+	if math.Abs(res-expected) >= tolerance {
+		t.Errorf("incorrect reaction enthalpy: expected %f; got %f", expected, res)
+	}
+}
+
+func TestReaction5Enthalpy(t *testing.T) {
+	res, expected := ammoniaCracking.enthalpy(0, 298.15), 91.80
 	if math.Abs(res-expected) >= tolerance {
 		t.Errorf("incorrect reaction enthalpy: expected %f; got %f", expected, res)
 	}
 }
+
+func TestReaction5PureAmmoniaFeed(t *testing.T) {
+	partials := []float64{aNH3: 2000, aN2: 0, aH2: 0}
+	if rate := reaction5(773.15, partials); math.IsNaN(rate) || math.IsInf(rate, 0) || rate <= 0 {
+		t.Errorf("expected finite forward rate; got %f", rate)
+	}
+}
+
+func TestRatesFiniteForDryMethanatorFeed(t *testing.T) {
+	partials := []float64{sCO: 0, sH2: 2700, sCH4: 0, sCO2: 0, sH2O: 0, sC2H6: 0}
+	T := 573.15
+	denominator := _denominator(T, partials)
+	for i, rate := range []float64{
+		reaction1(T, denominator, partials),
+		reaction2(T, denominator, partials),
+		reaction3(T, denominator, partials),
+		reaction4(T, partials),
+	} {
+		if math.IsNaN(rate) || math.IsInf(rate, 0) {
+			t.Errorf("reaction %d: expected finite rate; got %f", i+1, rate)
+		}
+	}
+}
+
+func TestRatesFiniteForPureMethaneSteamFeed(t *testing.T) {
+	partials := []float64{sCO: 0, sH2: 0, sCH4: 5.6, sCO2: 0, sH2O: 17.9, sC2H6: 0}
+	dFdW := make([]float64, len(steamReforming.species))
+	rates := make([]float64, len(steamReforming.reactions))
+	for _, T := range []float64{700, 823.15, 1100} {
+		steamReforming.rates(T, partials, rates)
+		for j, rate := range rates {
+			if math.IsNaN(rate) || math.IsInf(rate, 0) || rate < 0 {
+				t.Errorf("reaction %d at %.2f K: expected finite forward rate; got %f", j+1, T, rate)
+			}
+		}
+		steamReforming.derivatives(units.Temperature(T), partials, rates, dFdW)
+		if dFdW[1] <= 0 {
+			t.Errorf("expected hydrogen production at %.2f K; got %f", T, dFdW[1])
+		}
+	}
+}
+
+func TestSpeciesPositions(t *testing.T) {
+	for i, compound := range map[int]string{sCO: "CO", sH2: "H2", sCH4: "CH4", sCO2: "CO2", sH2O: "H2O", sC2H6: "C2H6"} {
+		if steamReforming.species[i] != compound {
+			t.Errorf("expected %s at position %d of steam reforming; got %s", compound, i, steamReforming.species[i])
+		}
+	}
+	for i, compound := range map[int]string{aNH3: "NH3", aN2: "N2", aH2: "H2"} {
+		if ammoniaCracking.species[i] != compound {
+			t.Errorf("expected %s at position %d of ammonia cracking; got %s", compound, i, ammoniaCracking.species[i])
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// Package reactor models a fired tubular reactor packed with catalyst, such
+// as a steam reformer, as a plug flow system integrated over catalyst mass.
+// A single representative tube is simulated; feed and heating gas flows are
+// totals for the reactor and are shared equally between its tubes.
+package reactor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/ode"
+)
+
+// Geometry describes the reactor tubes.
+type Geometry struct {
+	D      float64 // tube diameter (m)
+	Length float64 // tube length (m)
+	Tubes  float64 // number of tubes
+}
+
+// Catalyst describes the packed bed.
+type Catalyst struct {
+	Density      float64 // bulk density (kg/m^3)
+	Voidage      float64 // bed voidage
+	Dp           float64 // particle diameter (m)
+	HeatCapacity float64 // J/kgK, used by transient simulations
+}
+
+// Gas holds the process gas properties used in the Ergun pressure drop and
+// in gas–solid heat transfer.
+type Gas struct {
+	Density      float64 // kg/m^3
+	Viscosity    float64 // Pa s
+	Conductivity float64 // W/mK
+	Diffusivity  float64 // of the reactants, for gas-solid mass transfer (m^2/s)
+}
+
+// Arrangement is the direction of heating gas flow relative to the process
+// gas.
+type Arrangement string
+
+const (
+	CoCurrent      Arrangement = "co-current"
+	CounterCurrent Arrangement = "counter-current"
+)
+
+// Heating describes heat transfer through the tube wall. Talpha is the
+// temperature at which the heating gas enters, which is at the process outlet
+// for CounterCurrent flow; an empty Arrangement is CoCurrent and an empty Mode
+// is HeatingGas. With WallTemperature or HeatFlux, Profile gives the wall
+// temperature (K) or heat flux (W/m^2) against axial position in place of the
+// heating gas, and Talpha and Arrangement are unused.
+type Heating struct {
+	U           float64 // heat transfer coefficient (W/m^2K)
+	Talpha      float64 // inlet heating gas (or coolant) temperature (K)
+	Adiabatic   bool    // removes heat transfer through the wall
+	Arrangement Arrangement
+	Mode        HeatingMode
+	Profile     Table
+	// Correlation, if not empty, finds U along the tube from the bed-side
+	// wall coefficient, conduction through the Wall and Outside in series,
+	// in place of the fixed U.
+	Correlation WallCorrelation
+	Outside     float64 // heating gas to outer wall coefficient (W/m^2K)
+	// FluxFactor multiplies the heat flux into the tube, e.g. for tubes
+	// that see more or less of the furnace than the average; zero leaves it
+	// unchanged.
+	FluxFactor float64
+}
+
+// BedModel selects the description of the catalyst bed.
+type BedModel string
+
+const (
+	// PlugFlow is the one-dimensional pseudo-homogeneous model.
+	PlugFlow BedModel = "plug-flow"
+	// Radial2D is the two-dimensional pseudo-homogeneous model, which
+	// resolves radial temperature and composition gradients.
+	Radial2D BedModel = "radial"
+	// Heterogeneous is the one-dimensional model with the catalyst at its own
+	// temperature and surface composition, coupled to the gas by Wakao–Kaguei
+	// heat transfer and Wakao–Funazkri mass transfer.
+	Heterogeneous BedModel = "heterogeneous"
+	// AxialDispersion is the one-dimensional pseudo-homogeneous model with
+	// axial dispersion of mass and heat, solved as a boundary-value problem.
+	AxialDispersion BedModel = "dispersion"
+)
+
+// Radial describes the two-dimensional bed. Heating.U is then the coefficient
+// from the heating gas to the inside of the wall, in series with Hw, which a
+// Heating.Correlation replaces.
+type Radial struct {
+	Nodes        int     // number of rings of equal width
+	Conductivity float64 // effective radial thermal conductivity, λer (W/mK)
+	Hw           float64 // bed-side wall heat transfer coefficient (W/m^2K)
+}
+
+// Furnace describes the firebox seen by the tubes in Radiation heating.
+type Furnace struct {
+	Pressure       float64 // kPa
+	BeamLength     float64 // mean beam length of the flue gas (m)
+	TubeEmissivity float64
+}
+
+// Dispersion describes the axial dispersion bed, whose Péclet numbers come
+// from the Edwards–Richardson (mass) and Yagi–Kunii–Wakao (heat) correlations.
+type Dispersion struct {
+	Cells int // number of finite volumes along the tube
+}
+
+// Solver configures the integrator of the gosl ode package. Zero initial
+// step and maximum steps keep the defaults of gosl, as do zero tolerances,
+// unless only one of them is set, which then sets the other too. An empty
+// Method is radau5.
+type Solver struct {
+	Method      string  // e.g. radau5, dopri5, dopri8 or bweuler
+	Atol, Rtol  float64 // absolute and relative tolerances
+	InitialStep float64 // catalyst mass (kg), or time (s) in transients
+	MaxSteps    int     // per integration
+	// AnalyticJacobian gives implicit methods the exact Jacobian of the plug
+	// flow bed in place of finite differences. It has not been measured to
+	// be faster, so finite differences remain the default.
+	AnalyticJacobian bool
+}
+
+// methods lists the methods of the gosl ode package.
+var methods = []string{"fweuler", "bweuler", "moeuler", "rk2", "rk3", "heun3", "rk4", "merson4", "zonneveld4", "fehlberg4", "dopri5", "verner6", "fehlberg7", "dopri8", "radau5"}
+
+// SolverStats counts the work of the integrator over every integration of a
+// simulation, of which shooting and relaxation need several.
+type SolverStats struct {
+	Integrations        int
+	Steps               int
+	Accepted, Rejected  int
+	FunctionEvaluations int
+	JacobianEvaluations int
+	Decompositions      int
+}
+
+// Kinetics adjusts the rate laws of the selected mode.
+type Kinetics struct {
+	// Factors scales the rate of each reaction, in the order of the
+	// mechanism, e.g. for catalyst activity; nil leaves the rates unchanged.
+	Factors []float64
+}
+
+// Config describes a reactor simulation.
+type Config struct {
+	Mode       Mode
+	Feed       map[string]float64 // total process gas feed (mol/s)
+	T          float64            // inlet temperature (K)
+	P          float64            // inlet pressure (kPa)
+	Geometry   Geometry
+	Catalyst   Catalyst
+	Gas        Gas
+	Heating    Heating
+	Flue       map[string]float64 // total heating gas flows of N2, CO2, H2O and O2 (mol/s)
+	Furnace    Furnace            // used by Radiation heating
+	Wall       Wall               // used by Heating.Correlation and for creep life
+	Kinetics   Kinetics
+	Bed        BedModel   // an empty Bed is PlugFlow
+	Radial     Radial     // used by the Radial2D bed
+	Dispersion Dispersion // used by the AxialDispersion bed and transients
+	Transient  Transient  // used by SimulateTransient
+	Solver     Solver
+
+	// Zones, if not nil, describe the tube from the process inlet in place
+	// of Geometry.Length and, where they are set, D, Catalyst and Kinetics.
+	Zones []Zone
+
+	// Events are located along the tube and reported in Profile.Events.
+	Events []Event
+}
+
+// DefaultConfig returns the configuration of the reference steam reformer.
+func DefaultConfig() Config {
+	return Config{
+		Mode: Reforming,
+		Feed: map[string]float64{
+			"CH4":  106,
+			"H2":   6.57,
+			"CO":   0.001,
+			"CO2":  2.988,
+			"H2O":  383,
+			"C2H6": 10,
+		},
+		T:        823.15,
+		P:        2350,
+		Geometry: Geometry{D: 0.11, Length: 15, Tubes: 200},
+		Catalyst: Catalyst{Density: 870, Voidage: 0.44, Dp: 0.013, HeatCapacity: 1000},
+		Gas:      Gas{Density: 6.38, Viscosity: 0.00002, Conductivity: 0.1, Diffusivity: 8e-6},
+		Heating:  Heating{U: 40, Talpha: 2000, Outside: 50},
+		Flue: map[string]float64{
+			"N2":  738.5,
+			"CO2": 137.15,
+			"H2O": 137.15,
+			"O2":  42.2,
+		},
+		Wall:       Wall{Thickness: 0.012, Conductivity: 25},
+		Furnace:    Furnace{Pressure: 101.325, BeamLength: 3, TubeEmissivity: 0.85},
+		Bed:        PlugFlow,
+		Radial:     Radial{Nodes: 8, Conductivity: 5, Hw: 800},
+		Dispersion: Dispersion{Cells: 40},
+		// an HP alloy tube with 12 mm walls
+		Transient: Transient{Duration: 3600, Interval: 60, WallMass: 36, WallHeatCapacity: 550},
+		Solver:    Solver{Method: "radau5"},
+	}
+}
+
+// Simulate integrates a tube of the reactor described by c from inlet to
+// outlet. Counter-current heating is solved as a boundary-value problem by
+// shooting on the heating gas outlet temperature, and the AxialDispersion bed
+// by relaxation. The events of c are then located along the tube. Simulate
+// stops early, returning ctx.Err(), if ctx is cancelled.
+func Simulate(ctx context.Context, c Config) (*Profile, error) {
+	m, err := newModel(c)
+	if err != nil {
+		return nil, err
+	}
+	var wValues []float64
+	var yValues [][]float64
+	var iterations int
+	switch {
+	case m.Bed == AxialDispersion:
+		wValues, yValues, err = m.relax(ctx)
+	case m.counterCurrent():
+		wValues, yValues, iterations, err = m.shoot(ctx)
+	default:
+		wValues, yValues, err = m.integrate(ctx, m.initial())
+	}
+	if err != nil {
+		return nil, err
+	}
+	wValues, yValues, occurrences, err := m.events(ctx, wValues, yValues)
+	if err != nil {
+		return nil, err
+	}
+	p := m.profile(wValues, yValues)
+	p.ShootingIterations = iterations
+	p.Events = occurrences
+	p.Stats = *m.stats
+	return p, nil
+}
+
+// integrate solves the model as an initial-value problem from the inlet state
+// y0, returning the solver steps and the transposed table of states. It ends
+// at the first step past an event that stops the tube.
+func (m *model) integrate(ctx context.Context, y0 []float64) (wValues []float64, yValues [][]float64, err error) {
+	if m.zones != nil {
+		return m.integrateZones(ctx, y0)
+	}
+	return m.solve(ctx, m.ODEs, m.jacobian(), y0, 0, m.W, m.stopper())
+}
+
+// solve integrates fcn from y0 at x0 to xf with the configured method,
+// returning the solver steps and the transposed table of states and adding
+// to the statistics of m. A nil jac is approximated by finite differences.
+// The integration ends early once stop, if not nil, reports an event.
+func (m *model) solve(ctx context.Context, fcn ode.Func, jac ode.JacF, y0 []float64, x0, xf float64, stop *stopper) (xValues []float64, yValues [][]float64, err error) {
+	method := m.Solver.Method
+	if method == "" {
+		method = "radau5"
+	}
+	config := ode.NewConfig(method, "", nil)
+	if m.Solver.Atol > 0 || m.Solver.Rtol > 0 {
+		atol, rtol := m.Solver.Atol, m.Solver.Rtol
+		if atol == 0 {
+			atol = rtol
+		}
+		if rtol == 0 {
+			rtol = atol
+		}
+		config.SetTols(atol, rtol)
+	}
+	if m.Solver.InitialStep > 0 {
+		config.IniH = m.Solver.InitialStep
+	}
+	if m.Solver.MaxSteps > 0 {
+		config.NmaxSS = m.Solver.MaxSteps
+	}
+	config.SetStepOut(true, func(istep int, h, x float64, y la.Vector) bool {
+		return ctx.Err() != nil || (stop != nil && stop.step(x, y))
+	})
+
+	y := la.NewVectorSlice(append([]float64(nil), y0...))
+	solver := ode.NewSolver(len(y), config, fcn, jac, nil)
+	defer solver.Free()
+	defer func() {
+		stat := solver.Stat
+		m.stats.Integrations++
+		m.stats.Steps += stat.Nsteps
+		m.stats.Accepted += stat.Naccepted
+		m.stats.Rejected += stat.Nrejected
+		m.stats.FunctionEvaluations += stat.Nfeval
+		m.stats.JacobianEvaluations += stat.Njeval
+		m.stats.Decompositions += stat.Ndecomp
+		// gosl panics when the integration fails
+		if r := recover(); r != nil {
+			xValues, yValues, err = nil, nil, fmt.Errorf("reactor: integration failed: %v", r)
+		}
+	}()
+	solver.Solve(y, x0, xf)
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return solver.Out.GetStepX(), solver.Out.GetStepYtableT(), nil
+}
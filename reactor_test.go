@@ -0,0 +1,365 @@
+package reactor
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ewancook/reactor/units"
+)
+
+func TestSimulate(t *testing.T) {
+	p, err := Simulate(context.Background(), DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := len(p.W) - 1
+	if conversion := p.Conversion()[last]; conversion <= 0 || conversion >= 1 {
+		t.Errorf("expected methane conversion between 0 and 1; got %f", conversion)
+	}
+	if p.T[last] <= p.T[0] || p.P[last] >= p.P[0] {
+		t.Errorf("expected temperature to rise and pressure to fall; got T %f -> %f, P %f -> %f", p.T[0], p.T[last], p.P[0], p.P[last])
+	}
+	if p.ElementClosure > 1e-9 || p.EnergyClosure > 1e-3 {
+		t.Errorf("poor closure: element %e; energy %e", p.ElementClosure, p.EnergyClosure)
+	}
+}
+
+func TestSimulateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Simulate(ctx, DefaultConfig()); err != context.Canceled {
+		t.Errorf("expected %v; got %v", context.Canceled, err)
+	}
+}
+
+func TestSimulateInvalidConfig(t *testing.T) {
+	for name, modify := range map[string]func(*Config){
+		"mode":    func(c *Config) { c.Mode = "cooking" },
+		"species": func(c *Config) { c.Feed["NH3"] = 1 },
+		"tubes":   func(c *Config) { c.Geometry.Tubes = 0 },
+		"factors": func(c *Config) { c.Kinetics.Factors = []float64{1} },
+		"heating": func(c *Config) { c.Heating.Mode = "solar" },
+		"bed":     func(c *Config) { c.Bed = "fluidised" },
+		"cells":   func(c *Config) { c.Bed, c.Dispersion.Cells = AxialDispersion, 1 },
+		"table":   func(c *Config) { c.Heating.Mode = HeatFlux },
+		"furnace": func(c *Config) { c.Heating.Mode, c.Furnace.TubeEmissivity = Radiation, 0 },
+		"wall":    func(c *Config) { c.Heating.Correlation = "guess" },
+		"method":  func(c *Config) { c.Solver.Method = "guess" },
+		"rtol":    func(c *Config) { c.Solver.Rtol = -1 },
+	} {
+		c := DefaultConfig()
+		modify(&c)
+		if _, err := Simulate(context.Background(), c); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSimulateCounterCurrent(t *testing.T) {
+	c := DefaultConfig()
+	c.Heating.Talpha = 1300
+	c.Heating.Arrangement = CounterCurrent
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := len(p.W) - 1
+	if math.Abs(p.Talpha[last]-c.Heating.Talpha) > shootingTolerance {
+		t.Errorf("expected heating gas to enter at %f; got %f", c.Heating.Talpha, p.Talpha[last])
+	}
+	if p.Talpha[0] >= p.Talpha[last] {
+		t.Errorf("expected heating gas to cool towards the process inlet; got %f -> %f", p.Talpha[last], p.Talpha[0])
+	}
+	if p.EnergyClosure > 1e-3 {
+		t.Errorf("poor energy closure: %e", p.EnergyClosure)
+	}
+}
+
+func TestShootBracketEnds(t *testing.T) {
+	// with no heat transfer the heating gas leaves as it enters, so the end
+	// of the bracket at Talpha is the solution
+	for _, Talpha := range []float64{700, 1300} {
+		c := DefaultConfig()
+		c.Heating.U, c.Heating.Talpha = 1e-9, Talpha
+		c.Heating.Arrangement = CounterCurrent
+		m, err := newModel(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, yValues, iterations, err := m.shoot(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		outlet := yValues[m.iTα()][len(yValues[m.iTα()])-1]
+		if math.Abs(outlet-Talpha) > shootingTolerance {
+			t.Errorf("Talpha %f: expected heating gas to enter at Talpha; got %f", Talpha, outlet)
+		}
+		if expected := map[float64]int{700: 1, 1300: 2}[Talpha]; iterations != expected {
+			t.Errorf("Talpha %f: expected %d integrations; got %d", Talpha, expected, iterations)
+		}
+	}
+}
+
+func TestSimulatePrescribedHeating(t *testing.T) {
+	profiles := map[HeatingMode]Table{
+		WallTemperature: {Z: []float64{0, 5, 15}, Values: []float64{950, 1100, 1150}},
+		HeatFlux:        {Z: []float64{0, 3, 15}, Values: []float64{60000, 80000, 40000}},
+	}
+	for mode, table := range profiles {
+		c := DefaultConfig()
+		c.Heating.U = 500
+		c.Heating.Mode = mode
+		c.Heating.Profile = table
+		p, err := Simulate(context.Background(), c)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		last := len(p.W) - 1
+		z := p.W[last] / (c.Catalyst.Density * math.Pi * c.Geometry.D * c.Geometry.D / 4)
+		switch mode {
+		case WallTemperature:
+			if math.Abs(p.Talpha[last]-table.At(z)) > 1e-3 {
+				t.Errorf("%s: expected outlet wall temperature %f; got %f", mode, table.At(z), p.Talpha[last])
+			}
+		case HeatFlux:
+			if math.Abs(p.WallFlux[last]-table.At(z)) > 1e-6 {
+				t.Errorf("%s: expected outlet flux %f; got %f", mode, table.At(z), p.WallFlux[last])
+			}
+		}
+		if p.EnergyClosure > 1e-3 {
+			t.Errorf("%s: poor energy closure: %e", mode, p.EnergyClosure)
+		}
+	}
+}
+
+func TestSimulateRadial(t *testing.T) {
+	plug, err := Simulate(context.Background(), DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := DefaultConfig()
+	c.Bed = Radial2D
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := len(p.W) - 1
+	if centre, wall := p.RadialT[0][last], p.RadialT[c.Radial.Nodes-1][last]; centre >= wall {
+		t.Errorf("expected the centre to be colder than the wall; got %f and %f", centre, wall)
+	}
+	if p.ElementClosure > 1e-9 || p.EnergyClosure > 1e-3 {
+		t.Errorf("poor closure: element %e; energy %e", p.ElementClosure, p.EnergyClosure)
+	}
+
+	// a well-mixed cross section recovers plug flow
+	c.Radial = Radial{Nodes: 4, Conductivity: 1e4, Hw: 1e7}
+	p, err = Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, res := plug.Conversion()[len(plug.W)-1], p.Conversion()[len(p.W)-1]
+	if math.Abs(res-expected) > 1e-3 {
+		t.Errorf("expected conversion %f; got %f", expected, res)
+	}
+}
+
+func TestSimulateHeterogeneous(t *testing.T) {
+	c := DefaultConfig()
+	c.Bed = Heterogeneous
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Ts[0] >= p.T[0] {
+		t.Errorf("expected the catalyst to be colder than the gas at the inlet; got %f and %f", p.Ts[0], p.T[0])
+	}
+	if p.ElementClosure > 1e-9 || p.EnergyClosure > 1e-3 {
+		t.Errorf("poor closure: element %e; energy %e", p.ElementClosure, p.EnergyClosure)
+	}
+}
+
+func TestReactionProfiles(t *testing.T) {
+	for _, bed := range []BedModel{Radial2D, Heterogeneous} {
+		c := DefaultConfig()
+		c.Bed = bed
+		m, err := newModel(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wValues, states, err := m.integrate(context.Background(), m.initial())
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := m.profile(wValues, states)
+		// dispersion between the rings cancels, so the species gradients of
+		// the whole tube follow from the reported rates alone
+		y, f := make([]float64, len(states)), make([]float64, len(states))
+		for _, step := range []int{len(wValues) / 10, len(wValues) / 2} {
+			for i := range y {
+				y[i] = states[i][step]
+			}
+			m.ODEs(f, 0, wValues[step], y)
+			for i, compound := range m.mech.species {
+				var gradient, expected float64
+				for j := 0; j < m.rings; j++ {
+					gradient += f[j*(m.n+1)+i]
+				}
+				for j, ν := range m.mech.ν {
+					expected += ν[i] * p.Reactions[j].Rate[step]
+				}
+				if math.Abs(gradient-expected) > 1e-9*math.Max(1, math.Abs(expected)) {
+					t.Errorf("%s: step %d: %s: the gradient is %e mol/s/kg; the rates give %e", bed, step, compound, gradient, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestSurfaceFilm(t *testing.T) {
+	c := DefaultConfig()
+	c.Bed = Heterogeneous
+	surface := func(c Config) (*model, []float64) {
+		m, err := newModel(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y := m.initial()
+		m.mech.partials(y, m.partials)
+		hv, kv := m.transfer(y)
+		dFdW := make([]float64, m.n)
+		m.surface(m.T0, hv, kv, dFdW)
+		film := (units.Pressure(R*c.T/kv) * units.Pa).In(m.mech.pressure)
+		for i, p := range m.pellet {
+			if Δ := p - m.partials[i] - film*dFdW[i]; math.Abs(Δ) > 1e-9 {
+				t.Errorf("%s: film balance not met by %e", m.mech.species[i], Δ)
+			}
+		}
+		return m, dFdW
+	}
+	m, _ := surface(c)
+	C2H6, H2 := m.mech.index("C2H6"), m.mech.index("H2")
+	if m.pellet[C2H6] >= m.partials[C2H6]/2 || m.pellet[H2] <= m.partials[H2] {
+		t.Errorf("expected the film to limit ethane reforming at the inlet; C2H6 %f of %f bar, H2 %f of %f bar",
+			m.pellet[C2H6], m.partials[C2H6], m.pellet[H2], m.partials[H2])
+	}
+
+	// a film without resistance leaves the surface at the bulk composition
+	c.Gas.Diffusivity = 1e3
+	m, _ = surface(c)
+	for i, p := range m.pellet {
+		if math.Abs(p-m.partials[i]) > 1e-4*c.P/100 {
+			t.Errorf("%s: expected the bulk partial pressure %f; got %f", m.mech.species[i], m.partials[i], p)
+		}
+	}
+}
+
+func TestSimulateDispersion(t *testing.T) {
+	plug, err := Simulate(context.Background(), DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := DefaultConfig()
+	c.Bed = AxialDispersion
+	c.Dispersion.Cells = 12
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.W) != c.Dispersion.Cells+1 || math.Abs(p.W[len(p.W)-1]-plug.W[len(plug.W)-1]) > 1e-9 {
+		t.Errorf("expected %d steps to the outlet; got %d", c.Dispersion.Cells+1, len(p.W))
+	}
+	// the dispersion of a long bed is weak, leaving only that of the grid
+	expected, res := plug.Conversion()[len(plug.W)-1], p.Conversion()[len(p.W)-1]
+	if math.Abs(res-expected) > 0.05 {
+		t.Errorf("expected conversion near %f; got %f", expected, res)
+	}
+	if p.ElementClosure > 1e-9 || p.EnergyClosure > 1e-6 {
+		t.Errorf("poor closure: element %e; energy %e", p.ElementClosure, p.EnergyClosure)
+	}
+}
+
+func TestSimulateSolver(t *testing.T) {
+	c := DefaultConfig()
+	loose, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := loose.Stats
+	if s.Integrations != 1 || s.Steps != s.Accepted+s.Rejected || s.Accepted < 1 || s.FunctionEvaluations < s.Steps {
+		t.Errorf("implausible solver statistics: %+v", s)
+	}
+
+	c.Solver = Solver{Method: "radau5", Atol: 1e-8, Rtol: 1e-8, InitialStep: 1e-6}
+	tight, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tight.Stats.Accepted <= s.Accepted {
+		t.Errorf("expected tighter tolerances to take more steps than %d; got %d", s.Accepted, tight.Stats.Accepted)
+	}
+	// a tolerance set alone sets the other too
+	c.Solver = Solver{Method: "radau5", Rtol: 1e-8, InitialStep: 1e-6}
+	relative, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if relative.Stats.Accepted != tight.Stats.Accepted {
+		t.Errorf("expected Rtol alone to set Atol and take %d steps; got %d", tight.Stats.Accepted, relative.Stats.Accepted)
+	}
+	expected, res := tight.T[len(tight.T)-1], loose.T[len(loose.T)-1]
+	if math.Abs(res-expected) > 0.1 {
+		t.Errorf("expected an outlet temperature of %f K; got %f", expected, res)
+	}
+
+	c.Solver = Solver{MaxSteps: 3}
+	if _, err := Simulate(context.Background(), c); err == nil {
+		t.Errorf("expected an error when the steps run out")
+	}
+}
+
+func TestODEsAllocations(t *testing.T) {
+	radial := DefaultConfig()
+	radial.Bed = Radial2D
+	correlation := DefaultConfig()
+	correlation.Heating.Correlation = Dixon
+	radialCorrelation := radial
+	radialCorrelation.Heating.Correlation = Dixon
+	for name, c := range map[string]Config{
+		"plug flow": DefaultConfig(), "radial": radial, "wall correlation": correlation, "radial wall correlation": radialCorrelation,
+	} {
+		m, err := newModel(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y := m.initial()
+		f := make([]float64, len(y))
+		if allocs := testing.AllocsPerRun(10, func() { m.ODEs(f, 0, 0, y) }); allocs != 0 {
+			t.Errorf("%s: expected the gradients not to allocate; got %.0f allocations", name, allocs)
+		}
+	}
+}
+
+func BenchmarkODEs(b *testing.B) {
+	m, err := newModel(DefaultConfig())
+	if err != nil {
+		b.Fatal(err)
+	}
+	y := m.initial()
+	f := make([]float64, len(y))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ODEs(f, 0, 0, y)
+	}
+}
+
+func BenchmarkSimulate(b *testing.B) {
+	c := DefaultConfig()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Simulate(context.Background(), c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
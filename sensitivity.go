@@ -0,0 +1,261 @@
+package reactor
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/ode"
+)
+
+// sensitivityStep is the step in each input, relative to its value, over
+// which the sensitivity equations are differenced.
+const sensitivityStep = 1e-4
+
+// Sensitivity holds the normalised sensitivity coefficients (v/x)(∂x/∂v) of
+// outputs x of one tube to an input of value v, at every step of a Profile.
+// A coefficient is zero where its output is zero, such as the conversion and
+// pressure drop at the inlet.
+type Sensitivity struct {
+	Input string // named as for Config.Input
+	Value float64
+
+	Conversion   []float64
+	T            []float64 // process gas (cup-mixing) temperature
+	PressureDrop []float64
+	Flows        map[string][]float64
+}
+
+// perturbation holds the models of a tube with one input stepped by ±δ.
+type perturbation struct {
+	up, down *model
+	δ        float64
+}
+
+// Sensitivities simulates the tube described by c along with the forward
+// sensitivities of its state to each of the named inputs, returning the
+// Profile and a Sensitivity for each input. The sensitivity equations,
+//
+//	ds/dW = ∂f/∂y s + ∂f/∂v,
+//
+// are integrated with the state, so that the solver controls their error
+// too, each right-hand side being found as the central difference of the
+// gradients f along (s, 1) in the state and input. Counter-current heating
+// and the AxialDispersion bed, which are not initial-value problems, are not
+// supported, nor are D and l, which change the catalyst mass over which the
+// state is found, and Events are not located.
+func Sensitivities(ctx context.Context, c Config, inputs []string) (*Profile, []Sensitivity, error) {
+	m, err := newModel(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m.counterCurrent() || m.Bed == AxialDispersion {
+		return nil, nil, fmt.Errorf("reactor: sensitivities need co-current heating and a bed other than dispersion")
+	}
+	perturbations := make([]perturbation, len(inputs))
+	for k, name := range inputs {
+		v, err := c.Input(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if massInput(name) {
+			return nil, nil, fmt.Errorf("reactor: the sensitivity to %s, which changes the catalyst mass, is not supported", name)
+		}
+		if v == 0 {
+			return nil, nil, fmt.Errorf("reactor: the sensitivity to %s needs a non-zero value", name)
+		}
+		stepped := func(v float64) (*model, error) {
+			c, err := c.WithInput(name, v)
+			if err != nil {
+				return nil, err
+			}
+			return newModel(c)
+		}
+		p := &perturbations[k]
+		p.δ = sensitivityStep * math.Abs(v)
+		if p.up, err = stepped(v + p.δ); err == nil {
+			p.down, err = stepped(v - p.δ)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reactor: sensitivity to %s: %v", name, err)
+		}
+	}
+
+	N := m.iTα() + 1
+	y0 := make([]float64, N*(1+len(inputs)))
+	copy(y0, m.initial())
+	for k, p := range perturbations {
+		up, down := p.up.initial(), p.down.initial()
+		for i := range up {
+			y0[(k+1)*N+i] = (up[i] - down[i]) / (2 * p.δ)
+		}
+	}
+	// zones are solved in turn, as by integrateZones, for the steps at their
+	// boundaries that split the profile
+	system := func(i int) (ode.Func, ode.JacF) {
+		return sensitivityODEs(m, perturbations, i), sensitivityJacobian(zoneOf(m, i), len(inputs))
+	}
+	var wValues []float64
+	var yValues [][]float64
+	if m.zones != nil {
+		wValues, yValues, err = m.solveZones(ctx, y0, nil, system)
+	} else {
+		fcn, jac := system(-1)
+		wValues, yValues, err = m.solve(ctx, fcn, jac, y0, 0, m.W, nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profile := m.profile(wValues, yValues[:N])
+	profile.Stats = *m.stats
+	sensitivities := make([]Sensitivity, len(inputs))
+	y, plus, minus := make([]float64, N), make([]float64, N), make([]float64, N)
+	for k, p := range perturbations {
+		s := &sensitivities[k]
+		s.Input = inputs[k]
+		s.Value, _ = c.Input(inputs[k])
+		s.Conversion = make([]float64, len(wValues))
+		s.T = make([]float64, len(wValues))
+		s.PressureDrop = make([]float64, len(wValues))
+		s.Flows = map[string][]float64{}
+		for _, compound := range m.mech.species {
+			s.Flows[compound] = make([]float64, len(wValues))
+		}
+		for j, w := range wValues {
+			for i := range y {
+				y[i] = yValues[i][j]
+				plus[i] = y[i] + p.δ*yValues[(k+1)*N+i][j]
+				minus[i] = y[i] - p.δ*yValues[(k+1)*N+i][j]
+			}
+			x, up, down := outputs(m, w, y), outputs(p.up, w, plus), outputs(p.down, w, minus)
+			coefficient := func(i int) float64 {
+				if x[i] == 0 {
+					return 0
+				}
+				return s.Value / x[i] * (up[i] - down[i]) / (2 * p.δ)
+			}
+			s.Conversion[j], s.T[j], s.PressureDrop[j] = coefficient(0), coefficient(1), coefficient(2)
+			for i, compound := range m.mech.species {
+				s.Flows[compound][j] = coefficient(3 + i)
+			}
+		}
+	}
+	return profile, sensitivities, nil
+}
+
+// outputs returns the conversion, temperature, pressure drop and species
+// flows of m at catalyst mass w and state y, whose sensitivities are reported.
+func outputs(m *model, w float64, y []float64) []float64 {
+	s := m.state(w, y)
+	x := []float64{s.Conversion(), s.T(), m.P - s.P()}
+	for _, compound := range m.mech.species {
+		x = append(x, s.Flow(compound))
+	}
+	return x
+}
+
+// zoneOf returns zone i of m, or m itself for a negative i.
+func zoneOf(m *model, i int) *model {
+	if i < 0 {
+		return m
+	}
+	return m.zones[i]
+}
+
+// sensitivityODEs returns the gradients of the state of zone i of m followed
+// by those of its sensitivities to each perturbed input.
+func sensitivityODEs(m *model, perturbations []perturbation, i int) ode.Func {
+	N := m.iTα() + 1
+	plus, minus := make([]float64, N), make([]float64, N)
+	fPlus, fMinus := make([]float64, N), make([]float64, N)
+	zone := zoneOf(m, i)
+	ups, downs := make([]*model, len(perturbations)), make([]*model, len(perturbations))
+	for k, p := range perturbations {
+		ups[k], downs[k] = zoneOf(p.up, i), zoneOf(p.down, i)
+	}
+	return func(f la.Vector, h, x float64, y la.Vector) {
+		zone.ODEs(f[:N], h, x, y[:N])
+		for k, p := range perturbations {
+			s := y[(k+1)*N : (k+2)*N]
+			for i := range plus {
+				plus[i] = y[i] + p.δ*s[i]
+				minus[i] = y[i] - p.δ*s[i]
+			}
+			ups[k].ODEs(fPlus, h, x, plus)
+			downs[k].ODEs(fMinus, h, x, minus)
+			for i := range fPlus {
+				f[(k+1)*N+i] = (fPlus[i] - fMinus[i]) / (2 * p.δ)
+			}
+		}
+	}
+}
+
+// sensitivityJacobian returns the Jacobian of sensitivityODEs for m, or one
+// of its zones, with the given number of inputs, approximated, as is usual
+// for the simultaneous corrector, by repeating the Jacobian of the state down
+// the diagonal: the dependence of the sensitivity gradients on the state is
+// left out.
+func sensitivityJacobian(m *model, inputs int) ode.JacF {
+	N := m.iTα() + 1
+	J := make([][]float64, N)
+	for i := range J {
+		J[i] = make([]float64, N)
+	}
+	f := make([]float64, N)
+	return func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		m.stateJacobian(J, f, x, y[:N])
+		size := N * (1 + inputs)
+		if dfdy.Max() == 0 {
+			dfdy.Init(size, size, (1+inputs)*N*N)
+		}
+		dfdy.Start()
+		for b := 0; b <= inputs; b++ {
+			for i := range J {
+				for j, v := range J[i] {
+					if v != 0 {
+						dfdy.Put(b*N+i, b*N+j, v)
+					}
+				}
+			}
+		}
+	}
+}
+
+// WriteSensitivitiesCSV writes the sensitivities along the tube of profile p
+// to w, one row per step.
+func WriteSensitivitiesCSV(w io.Writer, p *Profile, sensitivities []Sensitivity) error {
+	header := []string{"W (kg)", "z (m)"}
+	for _, s := range sensitivities {
+		header = append(header, "conversion/"+s.Input, "T/"+s.Input, "pressure drop/"+s.Input)
+		for _, compound := range p.Species {
+			header = append(header, compound+"/"+s.Input)
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', 8, 64)
+	}
+	for step := range p.W {
+		row := []string{format(p.W[step]), format(p.Z[step])}
+		for _, s := range sensitivities {
+			row = append(row, format(s.Conversion[step]), format(s.T[step]), format(s.PressureDrop[step]))
+			for _, compound := range p.Species {
+				row = append(row, format(s.Flows[compound][step]))
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
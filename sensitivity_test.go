@@ -0,0 +1,95 @@
+package reactor
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSensitivities(t *testing.T) {
+	radial := DefaultConfig()
+	radial.Bed, radial.Radial.Nodes = Radial2D, 3
+	zoned := DefaultConfig()
+	zoned.Zones = []Zone{{Length: 5}, {Length: 10, D: 0.1}}
+	all := []string{"U", "Talpha", "Dp", "k1", "CH4", "H2O"}
+	for name, test := range map[string]struct {
+		c      Config
+		inputs []string
+	}{
+		"plug flow": {DefaultConfig(), all},
+		"radial":    {radial, []string{"U", "CH4"}},
+		"zones":     {zoned, all},
+	} {
+		c := test.c
+		p, sensitivities, err := Sensitivities(context.Background(), c, test.inputs)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		last := len(p.W) - 1
+		// the profile is that of Simulate, zone by zone
+		q, err := Simulate(context.Background(), c)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		qLast := len(q.W) - 1
+		if p.Z[last] != q.Z[qLast] || math.Abs(p.OuterWall[last]-q.OuterWall[qLast]) > 0.5 || math.Abs(p.T[last]-q.T[qLast]) > 0.5 {
+			t.Errorf("%s: expected the outlet of Simulate at %f m with outer wall %f K; got %f m and %f K",
+				name, q.Z[qLast], q.OuterWall[qLast], p.Z[last], p.OuterWall[last])
+		}
+		for _, s := range sensitivities {
+			if len(s.Conversion) != len(p.W) || s.Conversion[0] != 0 || s.PressureDrop[0] != 0 {
+				t.Errorf("%s, %s: expected coefficients at every step, zero at the inlet", name, s.Input)
+			}
+			// the outlet coefficients against differences of whole simulations
+			outlet := func(v float64) (conversion, T, drop float64) {
+				stepped, err := c.WithInput(s.Input, v)
+				if err != nil {
+					t.Fatal(err)
+				}
+				q, err := Simulate(context.Background(), stepped)
+				if err != nil {
+					t.Fatal(err)
+				}
+				k := len(q.W) - 1
+				return q.Conversion()[k], q.T[k], q.P[0] - q.P[k]
+			}
+			δ := 0.01 * s.Value
+			x1, T1, P1 := outlet(s.Value + δ)
+			x0, T0, P0 := outlet(s.Value - δ)
+			for _, check := range []struct {
+				output    string
+				got, want float64
+				tol       float64
+			}{
+				{"conversion", s.Conversion[last], s.Value * (x1 - x0) / (2 * δ) / p.Conversion()[last], 0.02},
+				{"T", s.T[last], s.Value * (T1 - T0) / (2 * δ) / p.T[last], 0.002},
+				{"pressure drop", s.PressureDrop[last], s.Value * (P1 - P0) / (2 * δ) / (p.P[0] - p.P[last]), 0.02},
+			} {
+				if math.Abs(check.got-check.want) > check.tol*math.Max(math.Abs(check.want), 0.05) {
+					t.Errorf("%s: %s sensitivity to %s is %g; differences give %g", name, check.output, s.Input, check.got, check.want)
+				}
+			}
+		}
+	}
+}
+
+func TestSensitivitiesInvalid(t *testing.T) {
+	counter := DefaultConfig()
+	counter.Heating.Arrangement = CounterCurrent
+	noEthane := DefaultConfig()
+	noEthane.Feed["C2H6"] = 0
+	for name, test := range map[string]struct {
+		c      Config
+		inputs []string
+	}{
+		"counter-current": {counter, []string{"U"}},
+		"zero input":      {noEthane, []string{"C2H6"}},
+		"unknown input":   {DefaultConfig(), []string{"NH3"}},
+		"catalyst mass":   {DefaultConfig(), []string{"D"}},
+		"reaction":        {DefaultConfig(), []string{"k5"}},
+	} {
+		if _, _, err := Sensitivities(context.Background(), test.c, test.inputs); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package reactor
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+const (
+	// shootingTolerance is the accepted mismatch (K) between the computed and
+	// specified heating gas inlet temperature in counter-current flow.
+	shootingTolerance     = 0.01
+	maxShootingIterations = 50
+)
+
+// shoot solves counter-current heating, where the heating gas enters at the
+// process outlet, as a two-point boundary-value problem. The heating gas
+// temperature at the process inlet is found such that integrating forward
+// reproduces Heating.Talpha at the outlet, using secant (Newton) steps that
+// fall back to bisection whenever they leave the bracket between the process
+// inlet temperature and Talpha. It returns the solver steps and transposed
+// table of the converged integration and the number of integrations needed.
+func (m *model) shoot(ctx context.Context) (wValues []float64, yValues [][]float64, iterations int, err error) {
+	residual := func(s float64) (float64, error) {
+		y0 := m.initial()
+		y0[m.iTα()] = s
+		var err error
+		wValues, yValues, err = m.integrate(ctx, y0)
+		if err != nil {
+			return 0, err
+		}
+		return yValues[m.iTα()][len(wValues)-1] - m.Heating.Talpha, nil
+	}
+
+	lo, hi := math.Min(m.T, m.Heating.Talpha), math.Max(m.T, m.Heating.Talpha)
+	rLo, err := residual(lo)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if math.Abs(rLo) < shootingTolerance {
+		return wValues, yValues, 1, nil
+	}
+	rHi, err := residual(hi)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if math.Abs(rHi) < shootingTolerance {
+		return wValues, yValues, 2, nil
+	}
+	if rLo*rHi > 0 {
+		return nil, nil, 0, fmt.Errorf("reactor: no heating gas outlet temperature between %.2f K and %.2f K meets Talpha", lo, hi)
+	}
+
+	s0, r0, s1, r1 := lo, rLo, hi, rHi
+	for i := 3; i <= maxShootingIterations; i++ {
+		s := s1 - r1*(s1-s0)/(r1-r0)
+		if !(s > lo && s < hi) {
+			s = (lo + hi) / 2
+		}
+		r, err := residual(s)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if math.Abs(r) < shootingTolerance {
+			return wValues, yValues, i, nil
+		}
+		if r*rLo > 0 {
+			lo, rLo = s, r
+		} else {
+			hi = s
+		}
+		s0, r0, s1, r1 = s1, r1, s, r
+	}
+	return nil, nil, 0, fmt.Errorf("reactor: counter-current shooting did not converge in %d iterations", maxShootingIterations)
+}
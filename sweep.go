@@ -0,0 +1,226 @@
+package reactor
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Grid holds the values an input takes in a sweep.
+type Grid struct {
+	Input  string // named as for Config.Input
+	Values []float64
+}
+
+// ParseGrid parses a grid written as an input name, "=" and either a
+// comma-separated list of values, e.g. "tubes=150,200", or an inclusive
+// range of start, stop and step, e.g. "T=800:900:25".
+func ParseGrid(s string) (Grid, error) {
+	parts := strings.SplitN(s, "=", 2)
+	g := Grid{Input: strings.TrimSpace(parts[0])}
+	if len(parts) != 2 || g.Input == "" {
+		return Grid{}, fmt.Errorf("reactor: grid %q is not of the form name=values", s)
+	}
+	values := parts[1]
+	if bounds := strings.Split(values, ":"); len(bounds) > 1 {
+		if len(bounds) != 3 {
+			return Grid{}, fmt.Errorf("reactor: grid %q: a range needs a start, stop and step", s)
+		}
+		var r [3]float64
+		for i, field := range bounds {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return Grid{}, fmt.Errorf("reactor: grid %q: %v", s, err)
+			}
+			r[i] = v
+		}
+		start, stop, step := r[0], r[1], r[2]
+		if step <= 0 || stop < start {
+			return Grid{}, fmt.Errorf("reactor: grid %q: a range needs a positive step from its start up to its stop", s)
+		}
+		// the stop is included despite rounding in the step
+		n := int(math.Floor((stop-start)/step+1e-9)) + 1
+		for i := 0; i < n; i++ {
+			g.Values = append(g.Values, start+float64(i)*step)
+		}
+		return g, nil
+	}
+	for _, field := range strings.Split(values, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return Grid{}, fmt.Errorf("reactor: grid %q: %v", s, err)
+		}
+		g.Values = append(g.Values, v)
+	}
+	return g, nil
+}
+
+// Sweep holds the outlet of a tube for every combination of the values of
+// its grids, the last grid varying fastest.
+type Sweep struct {
+	Grids   []Grid
+	Species []string
+	Cases   []Case
+}
+
+// Case is one run of a Sweep. Err records why it failed, leaving the rest of
+// its outlet zero.
+type Case struct {
+	Inputs       []float64 // the value of each grid
+	Conversion   float64
+	PressureDrop float64            // kPa
+	T            float64            // outlet temperature (K)
+	Flows        map[string]float64 // outlet flows of all the tubes (mol/s)
+	Err          error
+}
+
+// SimulateSweep simulates c with every combination of the values of grids on
+// a pool of workers, each building its own model for every case; workers
+// below one uses a worker for each CPU. A case that fails is recorded in its
+// Err, while a cancelled ctx stops the sweep and returns ctx.Err().
+func SimulateSweep(ctx context.Context, c Config, grids []Grid, workers int) (*Sweep, error) {
+	mech, err := mechanismFor(c.Mode)
+	if err != nil {
+		return nil, err
+	}
+	cases := 1
+	for _, g := range grids {
+		if _, err := c.Input(g.Input); err != nil {
+			return nil, err
+		}
+		if len(g.Values) == 0 {
+			return nil, fmt.Errorf("reactor: grid %s has no values", g.Input)
+		}
+		cases *= len(g.Values)
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	s := &Sweep{Grids: grids, Species: mech.species, Cases: make([]Case, cases)}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				s.Cases[k] = s.run(ctx, c, k)
+			}
+		}()
+	}
+	for k := range s.Cases {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- k
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// run simulates case k of the sweep of c.
+func (s *Sweep) run(ctx context.Context, c Config, k int) Case {
+	out := Case{Inputs: make([]float64, len(s.Grids))}
+	for i := len(s.Grids) - 1; i >= 0; i-- {
+		g := s.Grids[i]
+		out.Inputs[i] = g.Values[k%len(g.Values)]
+		k /= len(g.Values)
+	}
+	for i, g := range s.Grids {
+		if c, out.Err = c.WithInput(g.Input, out.Inputs[i]); out.Err != nil {
+			return out
+		}
+	}
+	p, err := Simulate(ctx, c)
+	if err != nil {
+		out.Err = err
+		return out
+	}
+	last := len(p.W) - 1
+	out.Conversion = p.Conversion()[last]
+	out.PressureDrop = p.P[0] - p.P[last]
+	out.T = p.T[last]
+	out.Flows = map[string]float64{}
+	for _, compound := range p.Species {
+		out.Flows[compound] = p.Outlet(compound)
+	}
+	return out
+}
+
+// WriteCSV writes the sweep to w, one row per case.
+func (s *Sweep) WriteCSV(w io.Writer) error {
+	var header []string
+	for _, g := range s.Grids {
+		header = append(header, g.Input)
+	}
+	header = append(header, "conversion", "pressure drop (kPa)", "outlet T (K)")
+	for _, compound := range s.Species {
+		header = append(header, compound+" (mol/s)")
+	}
+	header = append(header, "error")
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', 8, 64)
+	}
+	for _, c := range s.Cases {
+		var row []string
+		for _, v := range c.Inputs {
+			row = append(row, format(v))
+		}
+		row = append(row, format(c.Conversion), format(c.PressureDrop), format(c.T))
+		for _, compound := range s.Species {
+			row = append(row, format(c.Flows[compound]))
+		}
+		var message string
+		if c.Err != nil {
+			message = c.Err.Error()
+		}
+		if err := writer.Write(append(row, message)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the sweep to w as an array with an object for each case.
+func (s *Sweep) WriteJSON(w io.Writer) error {
+	type record struct {
+		Inputs       map[string]float64 `json:"inputs"`
+		Conversion   float64            `json:"conversion"`
+		PressureDrop float64            `json:"pressureDrop"`
+		T            float64            `json:"outletT"`
+		Flows        map[string]float64 `json:"flows,omitempty"`
+		Error        string             `json:"error,omitempty"`
+	}
+	records := make([]record, len(s.Cases))
+	for k, c := range s.Cases {
+		r := record{Inputs: map[string]float64{}, Conversion: c.Conversion, PressureDrop: c.PressureDrop, T: c.T, Flows: c.Flows}
+		for i, g := range s.Grids {
+			r.Inputs[g.Input] = c.Inputs[i]
+		}
+		if c.Err != nil {
+			r.Error = c.Err.Error()
+		}
+		records[k] = r
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
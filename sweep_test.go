@@ -0,0 +1,86 @@
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseGrid(t *testing.T) {
+	g, err := ParseGrid("T=800:900:25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Input != "T" || len(g.Values) != 5 || g.Values[4] != 900 {
+		t.Errorf("expected T from 800 to 900 K in five values; got %+v", g)
+	}
+	if g, err = ParseGrid("tubes=150, 200"); err != nil || len(g.Values) != 2 || g.Values[1] != 200 {
+		t.Errorf("expected tubes of 150 and 200; got %+v (%v)", g, err)
+	}
+	for _, s := range []string{"T", "=1", "T=800:900", "T=900:800:25", "T=800:900:0", "T=hot"} {
+		if _, err := ParseGrid(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestSimulateSweep(t *testing.T) {
+	c := DefaultConfig()
+	grids := []Grid{{Input: "Talpha", Values: []float64{1900, 2000}}, {Input: "tubes", Values: []float64{150, 200, 0}}}
+	s, err := SimulateSweep(context.Background(), c, grids, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Cases) != 6 {
+		t.Fatalf("expected 6 cases; got %d", len(s.Cases))
+	}
+	p, err := Simulate(context.Background(), DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Talpha 2000 K and 200 tubes is the reference
+	last, got := len(p.W)-1, s.Cases[4]
+	if got.Inputs[0] != 2000 || got.Inputs[1] != 200 || got.Err != nil {
+		t.Fatalf("expected case 4 to be the reference; got %+v", got)
+	}
+	if got.Conversion != p.Conversion()[last] || got.T != p.T[last] || got.Flows["CH4"] != p.Outlet("CH4") {
+		t.Errorf("expected the outlet of the reference; got %+v", got)
+	}
+	if s.Cases[2].Err == nil || s.Cases[5].Err == nil {
+		t.Error("expected the cases without tubes to fail")
+	}
+	if s.Cases[0].T >= s.Cases[3].T {
+		t.Errorf("expected a hotter outlet with a hotter heating gas; got %f and %f K", s.Cases[0].T, s.Cases[3].T)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 7 || len(records[0]) != 2+3+len(s.Species)+1 {
+		t.Errorf("expected a header and 6 rows of %d columns; got %d rows of %d", 2+3+len(s.Species)+1, len(records), len(records[0]))
+	}
+	buf.Reset()
+	if err := s.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var cases []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &cases); err != nil || len(cases) != 6 {
+		t.Errorf("expected 6 cases of JSON; got %d (%v)", len(cases), err)
+	}
+
+	if _, err := SimulateSweep(context.Background(), c, []Grid{{Input: "Tin", Values: []float64{1}}}, 1); err == nil {
+		t.Error("expected an error for an unknown input")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SimulateSweep(ctx, c, grids, 2); err != context.Canceled {
+		t.Errorf("expected %v; got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,21 @@
+package thermo
+
+var elementData = map[string]map[string]float64{
+	"CO":   {"C": 1, "O": 1},
+	"H2O":  {"H": 2, "O": 1},
+	"H2":   {"H": 2},
+	"CO2":  {"C": 1, "O": 2},
+	"CH4":  {"C": 1, "H": 4},
+	"N2":   {"N": 2},
+	"O2":   {"O": 2},
+	"C2H6": {"C": 2, "H": 6},
+	"NH3":  {"N": 1, "H": 3},
+}
+
+// Elements lists the elements found in the supported compounds.
+var Elements = []string{"C", "H", "O", "N"}
+
+// Atoms returns the number of atoms of element in one molecule of compound.
+func Atoms(compound, element string) float64 {
+	return elementData[compound][element]
+}
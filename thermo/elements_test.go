@@ -0,0 +1,18 @@
+package thermo
+
+import "testing"
+
+func TestAtoms(t *testing.T) {
+	results := map[[2]string]float64{
+		{"CH4", "H"}:  4,
+		{"CO2", "O"}:  2,
+		{"C2H6", "C"}: 2,
+		{"NH3", "N"}:  1,
+		{"H2", "C"}:   0,
+	}
+	for k, expected := range results {
+		if res := Atoms(k[0], k[1]); res != expected {
+			t.Errorf("incorrect atoms of %s in %s: expected %.0f; got %.0f", k[1], k[0], expected, res)
+		}
+	}
+}
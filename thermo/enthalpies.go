@@ -1,9 +1,5 @@
 package thermo
 
-import (
-	"math"
-)
-
 var enthalpyData = map[string]func(float64) float64{
 	"CO":   hCarbonMonoxide,
 	"H2O":  hSteam,
@@ -11,15 +7,24 @@ var enthalpyData = map[string]func(float64) float64{
 	"CO2":  hCarbonDioxide,
 	"CH4":  hMethane,
 	"C2H6": hEthane,
+	"N2":   hNitrogen,
+	"O2":   hOxygen,
+	"NH3":  hAmmonia,
 }
 
 func Enthalpy(compound string, T float64) float64 {
 	return enthalpyData[compound](T)
 }
 
+// EnthalpyFunc returns the enthalpy of compound as a function of T, for
+// callers that evaluate it often and would rather look it up once.
+func EnthalpyFunc(compound string) func(T float64) float64 {
+	return enthalpyData[compound]
+}
+
 func hIntegral(T, A, B, C, D, E, F, G, H float64) float64 {
 	T = T / 1000
-	return A*T + math.Pow(T, 2)*B/2 + math.Pow(T, 3)*C/3 + math.Pow(T, 4)*D/4 - E/T + F - H
+	return T*(A+T*(B/2+T*(C/3+T*D/4))) - E/T + F - H
 }
 
 func hCarbonMonoxide(T float64) float64 {
@@ -47,8 +52,23 @@ func hMethane(T float64) float64 {
 	return -74.87 + hIntegral(T, a, b, c, d, e, f, g, h)
 }
 
+func hNitrogen(T float64) float64 {
+	a, b, c, d, e, f, g, h := shomateNitrogen(T)
+	return 0 + hIntegral(T, a, b, c, d, e, f, g, h)
+}
+
+func hOxygen(T float64) float64 {
+	a, b, c, d, e, f, g, h := shomateOxygen(T)
+	return 0 + hIntegral(T, a, b, c, d, e, f, g, h)
+}
+
+func hAmmonia(T float64) float64 {
+	a, b, c, d, e, f, g, h := shomateAmmonia(T)
+	return -45.90 + hIntegral(T, a, b, c, d, e, f, g, h)
+}
+
 func _ethaneIntegral(T float64) float64 {
-	return (7.56*T + 0.16*math.Pow(T, 2)/2 - 3.208*math.Pow(10, -5)*math.Pow(T, 3)/3 - 2.476*math.Pow(10, -8)*math.Pow(T, 4)/4 + 1.016*math.Pow(10, -11)*math.Pow(T, 5)/5) / 1000
+	return T * (7.56 + T*(0.16/2+T*(-3.208e-5/3+T*(-2.476e-8/4+T*1.016e-11/5)))) / 1000
 }
 
 func hEthane(T float64) float64 {
@@ -84,3 +84,45 @@ func TestMethaneEnthalpy(t *testing.T) {
 		_compareEnthalpy(t, res+74.87, expected)
 	}
 }
+
+func TestNitrogenEnthalpy(t *testing.T) {
+	results := map[float64]float64{
+		298:  0,
+		500:  5.91,
+		1000: 21.46,
+		2000: 56.14,
+		3000: 92.71,
+	}
+	for T, expected := range results {
+		res := Enthalpy("N2", T)
+		_compareEnthalpy(t, res, expected)
+	}
+}
+
+func TestOxygenEnthalpy(t *testing.T) {
+	results := map[float64]float64{
+		298:  0,
+		600:  9.25,
+		1000: 22.71,
+		2000: 59.18,
+		4000: 138.7,
+	}
+	for T, expected := range results {
+		res := Enthalpy("O2", T)
+		_compareEnthalpy(t, res, expected)
+	}
+}
+
+func TestAmmoniaEnthalpy(t *testing.T) {
+	results := map[float64]float64{
+		298:  -0.01,
+		500:  7.82,
+		1000: 32.64,
+		2000: 98.57,
+		3000: 174.9,
+	}
+	for T, expected := range results {
+		res := Enthalpy("NH3", T)
+		_compareEnthalpy(t, res+45.90, expected)
+	}
+}
@@ -0,0 +1,18 @@
+package thermo
+
+var molarMassData = map[string]float64{
+	"CO":   28.01,
+	"H2O":  18.015,
+	"H2":   2.016,
+	"CO2":  44.01,
+	"CH4":  16.04,
+	"N2":   28.014,
+	"O2":   31.998,
+	"C2H6": 30.069,
+	"NH3":  17.031,
+}
+
+// MolarMass returns the molar mass of a compound (g/mol).
+func MolarMass(compound string) float64 {
+	return molarMassData[compound]
+}
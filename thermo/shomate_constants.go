@@ -68,3 +68,12 @@ func shomateOxygen(T float64) (float64, float64, float64, float64, float64, floa
 		return 20.91111, 10.72071, -2.020498, 0.146449, 9.245722, 5.337651, 237.6185, 0.0
 	}
 }
+
+func shomateAmmonia(T float64) (float64, float64, float64, float64, float64, float64, float64, float64) {
+	switch {
+	case T <= 1400:
+		return 19.99563, 49.77119, -15.37599, 1.921168, 0.189174, -53.30667, 203.8591, -45.89806
+	default:
+		return 52.02427, 18.48801, -3.765128, 0.248541, -12.45799, -85.53895, 223.8022, -45.89806
+	}
+}
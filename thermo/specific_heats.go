@@ -1,9 +1,5 @@
 package thermo
 
-import (
-	"math"
-)
-
 var cpData = map[string]func(float64) float64{
 	"CO":   cpCarbonMonoxide,
 	"H2O":  cpSteam,
@@ -13,15 +9,22 @@ var cpData = map[string]func(float64) float64{
 	"N2":   cpNitrogen,
 	"O2":   cpOxygen,
 	"C2H6": cpEthane,
+	"NH3":  cpAmmonia,
 }
 
 func SpecificHeat(compound string, T float64) float64 {
 	return cpData[compound](T)
 }
 
+// SpecificHeatFunc returns the specific heat of compound as a function of T,
+// for callers that evaluate it often and would rather look it up once.
+func SpecificHeatFunc(compound string) func(T float64) float64 {
+	return cpData[compound]
+}
+
 func cpIntegral(T, A, B, C, D, E float64) float64 {
 	T = T / 1000
-	return A + B*T + C*math.Pow(T, 2) + D*math.Pow(T, 3) + E/math.Pow(T, 2)
+	return A + T*(B+T*(C+T*D)) + E/(T*T)
 }
 
 func cpCarbonMonoxide(T float64) float64 {
@@ -59,6 +62,46 @@ func cpOxygen(T float64) float64 {
 	return cpIntegral(T, a, b, c, d, e)
 }
 
+func cpAmmonia(T float64) float64 {
+	a, b, c, d, e, _, _, _ := shomateAmmonia(T)
+	return cpIntegral(T, a, b, c, d, e)
+}
+
 func cpEthane(T float64) float64 {
-	return 7.56 + T*0.16 - 3.208*math.Pow(10, -5)*T*T - 2.476*math.Pow(10, -8)*math.Pow(T, 3) + 1.016*math.Pow(10, -11)*math.Pow(T, 4)
+	return 7.56 + T*(0.16+T*(-3.208e-5+T*(-2.476e-8+T*1.016e-11)))
+}
+
+var shomateData = map[string]func(float64) (float64, float64, float64, float64, float64, float64, float64, float64){
+	"CO":  shomateCarbonMonoxide,
+	"H2O": shomateSteam,
+	"H2":  shomateHydrogen,
+	"CO2": shomateCarbonDioxide,
+	"CH4": shomateMethane,
+	"N2":  shomateNitrogen,
+	"O2":  shomateOxygen,
+	"NH3": shomateAmmonia,
+}
+
+// SpecificHeatSlope returns the derivative of SpecificHeat with respect to
+// temperature (J/molK^2).
+func SpecificHeatSlope(compound string, T float64) float64 {
+	return SpecificHeatSlopeFunc(compound)(T)
+}
+
+// SpecificHeatSlopeFunc returns SpecificHeatSlope of compound as a function
+// of T, looked up once as by SpecificHeatFunc.
+func SpecificHeatSlopeFunc(compound string) func(T float64) float64 {
+	if compound == "C2H6" {
+		return slopeEthane
+	}
+	shomate := shomateData[compound]
+	return func(T float64) float64 {
+		_, b, c, d, e, _, _, _ := shomate(T)
+		t := T / 1000
+		return (b + t*(2*c+t*3*d) - 2*e/(t*t*t)) / 1000
+	}
+}
+
+func slopeEthane(T float64) float64 {
+	return 0.16 + T*(-2*3.208e-5+T*(-3*2.476e-8+T*4*1.016e-11))
 }
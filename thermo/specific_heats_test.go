@@ -116,3 +116,30 @@ func TestOxygenSpecificHeat(t *testing.T) {
 		_compareSpecificHeat(t, res, expected)
 	}
 }
+
+func TestAmmoniaSpecificHeat(t *testing.T) {
+	results := map[float64]float64{
+		298:  35.64,
+		500:  42.03,
+		1000: 56.50,
+		1500: 66.59,
+		2000: 72.81,
+		3000: 78.93,
+	}
+	for T, expected := range results {
+		res := SpecificHeat("NH3", T)
+		_compareSpecificHeat(t, res, expected)
+	}
+}
+
+func TestSpecificHeatSlope(t *testing.T) {
+	for compound := range cpData {
+		for _, T := range []float64{400, 900, 1500} {
+			const δ = 1e-3
+			expected := (SpecificHeat(compound, T+δ) - SpecificHeat(compound, T-δ)) / (2 * δ)
+			if res := SpecificHeatSlope(compound, T); math.Abs(res-expected) > 1e-6 {
+				t.Errorf("incorrect slope of the specific heat of %s at %.0f K: expected %f; got %f", compound, T, expected, res)
+			}
+		}
+	}
+}
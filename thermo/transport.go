@@ -0,0 +1,130 @@
+package thermo
+
+import (
+	"math"
+	"sort"
+)
+
+// R is the gas constant (J/molK).
+const R = 8.314
+
+// lennardJones holds the collision diameter (Å) and well depth over the
+// Boltzmann constant (K) of each compound (Poling, Prausnitz and O'Connell).
+var lennardJones = map[string][2]float64{
+	"CO":   {3.690, 91.7},
+	"H2O":  {2.641, 809.1},
+	"H2":   {2.827, 59.7},
+	"CO2":  {3.941, 195.2},
+	"CH4":  {3.758, 148.6},
+	"N2":   {3.798, 71.4},
+	"O2":   {3.467, 106.7},
+	"C2H6": {4.443, 215.7},
+	"NH3":  {2.900, 558.3},
+}
+
+// Viscosity returns the low-pressure viscosity of a compound (Pa s) at T (K)
+// from Chapman–Enskog theory, with the collision integral of Neufeld et al.
+func Viscosity(compound string, T float64) float64 {
+	return viscosity(lennardJones[compound], MolarMass(compound), T)
+}
+
+func viscosity(lj [2]float64, M, T float64) float64 {
+	Tr := T / lj[1]
+	Ω := 1.16145*math.Pow(Tr, -0.14874) + 0.52487*math.Exp(-0.77320*Tr) + 2.16178*math.Exp(-2.43787*Tr)
+	return 26.69e-7 * math.Sqrt(M*T) / (lj[0] * lj[0] * Ω)
+}
+
+// Conductivity returns the low-pressure thermal conductivity of a compound
+// (W/mK) at T (K) from its viscosity by the modified Eucken correlation.
+func Conductivity(compound string, T float64) float64 {
+	return conductivity(Viscosity(compound, T), SpecificHeat(compound, T), MolarMass(compound))
+}
+
+func conductivity(μ, cp, M float64) float64 {
+	Cv := cp - R
+	return μ / (M / 1000) * (1.32*Cv + 1.77*R)
+}
+
+// MixtureViscosity and MixtureConductivity return the properties of a gas of
+// the given mole fractions (or flows) at T (K) by the mixing rule of Wilke,
+// which Mason and Saxena extended to conductivity.
+func MixtureViscosity(fractions map[string]float64, T float64) float64 {
+	μ, _ := mixture(fractions, T)
+	return μ
+}
+
+func MixtureConductivity(fractions map[string]float64, T float64) float64 {
+	_, k := mixture(fractions, T)
+	return k
+}
+
+// mixture returns the viscosity and conductivity of a gas of the given
+// fractions, taking its compounds in sorted order so that the sums do not
+// depend on the order of the map.
+func mixture(fractions map[string]float64, T float64) (μ, k float64) {
+	species := make([]string, 0, len(fractions))
+	for compound := range fractions {
+		species = append(species, compound)
+	}
+	sort.Strings(species)
+	x := make([]float64, len(species))
+	for i, compound := range species {
+		x[i] = fractions[compound]
+	}
+	return NewMixture(species).Properties(x, T)
+}
+
+// Mixture evaluates the transport properties of gases of a fixed list of
+// species, looking each species up once, when the Mixture is made, and
+// reusing its own space, so that one Mixture must not be used concurrently.
+type Mixture struct {
+	species   []string
+	lj        [][2]float64
+	molarMass []float64
+	cp        []func(T float64) float64
+	μ, k      []float64
+}
+
+// NewMixture returns the Mixture of species.
+func NewMixture(species []string) *Mixture {
+	n := len(species)
+	m := &Mixture{
+		species:   species,
+		lj:        make([][2]float64, n),
+		molarMass: make([]float64, n),
+		cp:        make([]func(float64) float64, n),
+		μ:         make([]float64, n),
+		k:         make([]float64, n),
+	}
+	for i, compound := range species {
+		m.lj[i], m.molarMass[i], m.cp[i] = lennardJones[compound], MolarMass(compound), SpecificHeatFunc(compound)
+	}
+	return m
+}
+
+// Properties returns the viscosity (Pa s) and thermal conductivity (W/mK) of
+// a gas of the given mole fractions (or flows), in the order of the species
+// of m, at T (K) by the mixing rule of MixtureViscosity.
+func (m *Mixture) Properties(fractions []float64, T float64) (μ, k float64) {
+	var total float64
+	for i, x := range fractions {
+		total += x
+		m.μ[i] = viscosity(m.lj[i], m.molarMass[i], T)
+		m.k[i] = conductivity(m.μ[i], m.cp[i](T), m.molarMass[i])
+	}
+	for i, xi := range fractions {
+		if xi <= 0 {
+			continue
+		}
+		Mi := m.molarMass[i]
+		var sum float64
+		for j, xj := range fractions {
+			Mj := m.molarMass[j]
+			φ := math.Pow(1+math.Sqrt(m.μ[i]/m.μ[j])*math.Pow(Mj/Mi, 0.25), 2) / math.Sqrt(8*(1+Mi/Mj))
+			sum += xj / total * φ
+		}
+		μ += xi / total * m.μ[i] / sum
+		k += xi / total * m.k[i] / sum
+	}
+	return μ, k
+}
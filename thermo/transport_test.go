@@ -0,0 +1,57 @@
+package thermo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestViscosity(t *testing.T) {
+	for _, test := range []struct {
+		compound    string
+		T, expected float64 // K, Pa s
+	}{
+		{"N2", 300, 1.79e-5},
+		{"H2", 300, 8.95e-6},
+		{"CO2", 600, 2.70e-5},
+		{"CH4", 500, 1.69e-5},
+	} {
+		if res := Viscosity(test.compound, test.T); math.Abs(res-test.expected) > 0.05*test.expected {
+			t.Errorf("incorrect viscosity of %s at %.0f K: expected %e; got %e", test.compound, test.T, test.expected, res)
+		}
+	}
+}
+
+func TestConductivity(t *testing.T) {
+	for _, test := range []struct {
+		compound    string
+		T, expected float64 // K, W/mK
+	}{
+		{"N2", 300, 0.0260},
+		{"H2", 300, 0.187},
+		{"CO2", 600, 0.0407},
+		{"N2", 1000, 0.0667},
+	} {
+		if res := Conductivity(test.compound, test.T); math.Abs(res-test.expected) > 0.1*test.expected {
+			t.Errorf("incorrect conductivity of %s at %.0f K: expected %f; got %f", test.compound, test.T, test.expected, res)
+		}
+	}
+}
+
+func TestMixture(t *testing.T) {
+	pure := Viscosity("N2", 800)
+	if res := MixtureViscosity(map[string]float64{"N2": 3}, 800); math.Abs(res-pure) > 1e-12 {
+		t.Errorf("expected the viscosity of a pure gas %e; got %e", pure, res)
+	}
+	// hydrogen raises the conductivity of nitrogen but lowers its viscosity
+	mix := map[string]float64{"N2": 0.5, "H2": 0.5}
+	if res := MixtureConductivity(mix, 800); res <= Conductivity("N2", 800) || res >= Conductivity("H2", 800) {
+		t.Errorf("expected a conductivity between the pure gases; got %f", res)
+	}
+	if res := MixtureViscosity(mix, 800); res >= pure {
+		t.Errorf("expected hydrogen to lower the viscosity below %e; got %e", pure, res)
+	}
+	μ, k := NewMixture([]string{"H2", "N2", "CH4"}).Properties([]float64{1, 1, 0}, 800)
+	if μ != MixtureViscosity(mix, 800) || k != MixtureConductivity(mix, 800) {
+		t.Errorf("expected the indexed mixture to match the mixture by name; got %e and %f", μ, k)
+	}
+}
@@ -0,0 +1,294 @@
+package reactor
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cpmech/gosl/la"
+)
+
+// Transient describes a dynamic simulation by SimulateTransient.
+type Transient struct {
+	Duration float64 // s
+	Interval float64 // time between saved profiles (s)
+	Schedule Schedule
+
+	WallMass         float64 // tube wall per unit length (kg/m)
+	WallHeatCapacity float64 // J/kgK
+}
+
+// Schedule holds inputs that vary with time, each interpolated linearly
+// between its rows and held beyond them. Inputs are named as for
+// Config.Input, e.g. CH4, T, Talpha or flue:N2, other than D and l, which
+// would change the cells; inputs that are not scheduled keep their Config
+// values.
+type Schedule struct {
+	Times  []float64 // s, increasing
+	Inputs map[string][]float64
+}
+
+// ReadSchedule reads a Schedule from CSV with a header row of "t" followed by
+// input names, e.g. "t,CH4,Talpha".
+func ReadSchedule(r io.Reader) (Schedule, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return Schedule{}, err
+	}
+	if len(records) < 2 {
+		return Schedule{}, fmt.Errorf("reactor: schedule needs a header and at least one row")
+	}
+	header := records[0]
+	if strings.TrimSpace(header[0]) != "t" {
+		return Schedule{}, fmt.Errorf("reactor: schedule header starts with %q, not t", header[0])
+	}
+	s := Schedule{Inputs: map[string][]float64{}}
+	for i, record := range records[1:] {
+		for j, field := range record {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return Schedule{}, fmt.Errorf("reactor: row %d of schedule: %v", i+2, err)
+			}
+			if j == 0 {
+				s.Times = append(s.Times, v)
+				continue
+			}
+			name := strings.TrimSpace(header[j])
+			s.Inputs[name] = append(s.Inputs[name], v)
+		}
+	}
+	return s, nil
+}
+
+// apply returns a copy of c with the scheduled inputs at time t. The names
+// of the inputs have been checked by validate.
+func (s Schedule) apply(c Config, t float64) Config {
+	c = c.copyInputs()
+	for name, values := range s.Inputs {
+		c.setInput(name, Table{Z: s.Times, Values: values}.At(t))
+	}
+	return c
+}
+
+// validate checks the schedule by building the model of c at every row.
+func (s Schedule) validate(c Config) error {
+	if len(s.Times) == 0 && len(s.Inputs) == 0 {
+		return nil
+	}
+	if err := (Table{Z: s.Times, Values: s.Times}).validate(); err != nil {
+		return fmt.Errorf("reactor: schedule: %v", err)
+	}
+	for name, values := range s.Inputs {
+		if len(values) != len(s.Times) {
+			return fmt.Errorf("reactor: schedule has %d values of %s for %d times", len(values), name, len(s.Times))
+		}
+		if _, err := c.Input(name); err != nil {
+			return fmt.Errorf("reactor: schedule: %v", err)
+		}
+		if massInput(name) {
+			return fmt.Errorf("reactor: schedule cannot change %s during a transient", name)
+		}
+	}
+	for _, t := range s.Times {
+		if _, err := newModel(s.apply(c, t)); err != nil {
+			return fmt.Errorf("reactor: schedule at %g s: %v", t, err)
+		}
+	}
+	return nil
+}
+
+// History holds the axial profiles of a transient simulation at each saved
+// time. Their closures measure accumulation as well as error away from
+// steady state.
+type History struct {
+	Times    []float64 // s
+	Profiles []*Profile
+	Stats    SolverStats
+}
+
+// SimulateTransient starts from the steady state at the first scheduled
+// inputs and integrates the tube through time, saving its profile every
+// Transient.Interval. The tube is discretised into Dispersion.Cells finite
+// volumes as for the AxialDispersion bed, which is the only other bed it
+// supports. The process gas, catalyst and tube wall of each cell share a
+// temperature; gas holdup follows from the ideal gas law and makes the
+// composition and pressure respond over the gas residence time, which is
+// usually far shorter than the thermal response, while the heating gas is
+// quasi-steady. Events are not supported and are rejected.
+func SimulateTransient(ctx context.Context, c Config) (*History, error) {
+	switch c.Bed {
+	case "", PlugFlow, AxialDispersion:
+	default:
+		return nil, fmt.Errorf("reactor: transient simulation needs a plug flow or dispersion bed, not %q", c.Bed)
+	}
+	if c.Zones != nil {
+		return nil, fmt.Errorf("reactor: transient simulation needs a uniform tube, not zones")
+	}
+	if len(c.Events) > 0 {
+		return nil, fmt.Errorf("reactor: transient simulation does not support events")
+	}
+	tr := c.Transient
+	if tr.Duration <= 0 || tr.Interval <= 0 || tr.WallMass < 0 || tr.WallHeatCapacity < 0 || c.Catalyst.HeatCapacity < 0 {
+		return nil, fmt.Errorf("reactor: transient duration and interval must be positive and heat capacities not negative")
+	}
+	if c.Dispersion.Cells < 2 {
+		return nil, fmt.Errorf("reactor: transient simulation needs at least two cells")
+	}
+	if err := tr.Schedule.validate(c); err != nil {
+		return nil, err
+	}
+
+	start := tr.Schedule.apply(c, 0)
+	start.Bed = AxialDispersion
+	if c.Bed != AxialDispersion {
+		start.Bed = PlugFlow
+	}
+	m, err := newModel(start)
+	if err != nil {
+		return nil, err
+	}
+	y, err := m.guess(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if y, err = m.steady(ctx, y); err != nil {
+		return nil, err
+	}
+
+	h := &History{Times: []float64{0}, Profiles: []*Profile{m.profile(m.dispersionTable(y))}}
+	s := &scheduled{base: m, t: math.NaN()}
+	fcn := func(f la.Vector, _, t float64, y la.Vector) {
+		at, err := s.at(t)
+		if err != nil {
+			// the solver fails on the gradients, and the error is returned
+			for i := range f {
+				f[i] = math.NaN()
+			}
+			return
+		}
+		at.transientODEs(f, y)
+	}
+	for t := 0.0; t < tr.Duration; {
+		next := math.Min(t+tr.Interval, tr.Duration)
+		_, yValues, err := m.solve(ctx, fcn, m.banded(fcn), y, t, next, nil)
+		if s.err != nil {
+			return nil, s.err
+		}
+		if err != nil {
+			return nil, err
+		}
+		for j := range y {
+			y[j] = yValues[j][len(yValues[j])-1]
+		}
+		t = next
+		at, err := s.at(t)
+		if err != nil {
+			return nil, err
+		}
+		h.Times = append(h.Times, t)
+		h.Profiles = append(h.Profiles, at.profile(at.dispersionTable(y)))
+	}
+	h.Stats = *m.stats
+	return h, nil
+}
+
+// scheduled builds the models of a transient simulation with its scheduled
+// inputs. The model at the last time asked for is kept, since the solver
+// evaluates the gradients, and the banded Jacobian each of its columns, many
+// times at one time.
+type scheduled struct {
+	base  *model
+	t     float64
+	model *model
+	err   error // the first model that could not be built
+}
+
+// at returns the model of the base with the scheduled inputs at time t.
+func (s *scheduled) at(t float64) (*model, error) {
+	schedule := s.base.Transient.Schedule
+	switch {
+	case len(schedule.Inputs) == 0:
+		return s.base, nil
+	case t == s.t:
+		return s.model, nil
+	}
+	m, err := newModel(schedule.apply(s.base.Config, t))
+	if err != nil {
+		err = fmt.Errorf("reactor: schedule at %g s: %v", t, err)
+		if s.err == nil {
+			s.err = err
+		}
+		return nil, err
+	}
+	s.t, s.model = t, m
+	return m, nil
+}
+
+// flueRelaxation is the time constant (s) over which the heating gas
+// temperature of each cell relaxes to its energy balance. The furnace holds
+// no modelled volume of heating gas, so it is kept quasi-steady by relaxing
+// far faster than the process gas residence time in a cell.
+const flueRelaxation = 1e-3
+
+// transientODEs evaluates the time derivatives of the cell states y (per s)
+// by dividing the imbalance of each cell, from dispersionODEs, by its holdup.
+// The heating gas temperature is quasi-steady, relaxing over flueRelaxation.
+func (m *model) transientODEs(f la.Vector, y la.Vector) {
+	n, N := m.n, m.Dispersion.Cells
+	ΔW := m.W / float64(N)
+	Δz := m.Geometry.Length / float64(N)
+	volume := m.area * Δz
+	m.dispersionODEs(f, 0, 0, y)
+	for k := 0; k < N; k++ {
+		c, r := m.cell(y, k), m.cell(f, k)
+		flow, capacity, _, _ := m.axial(c)
+		// moles of gas in the voids of the cell
+		holdup := m.Catalyst.Voidage * volume * c[n+1] * 1000 / (R * c[n])
+		residence := holdup / flow
+		for i := range r[:n+2] {
+			if i == n {
+				continue
+			}
+			r[i] *= ΔW / residence
+		}
+		r[n+2] *= ΔW / flueRelaxation
+		heatCapacity := holdup*capacity/flow + ΔW*m.Catalyst.HeatCapacity + Δz*m.Transient.WallMass*m.Transient.WallHeatCapacity
+		r[n] *= capacity * ΔW / heatCapacity
+	}
+}
+
+// WriteCSV writes the history to w, one row per saved time and step.
+func (h *History) WriteCSV(w io.Writer) error {
+	first := h.Profiles[0]
+	header := []string{"t (s)", "W (kg)", "z (m)"}
+	for _, compound := range first.Species {
+		header = append(header, compound+" (mol/s)")
+	}
+	header = append(header, "T (K)", "P (kPa)", "Talpha (K)", "wall flux (W/m^2)")
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', 8, 64)
+	}
+	for k, p := range h.Profiles {
+		for step := range p.W {
+			row := []string{format(h.Times[k]), format(p.W[step]), format(p.Z[step])}
+			for _, series := range p.table() {
+				row = append(row, format(series[step]))
+			}
+			row = append(row, format(p.WallFlux[step]))
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,113 @@
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadSchedule(t *testing.T) {
+	s, err := ReadSchedule(strings.NewReader("t,Talpha,flue:N2\n0,2000,738.5\n60,2100,700\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.apply(DefaultConfig(), 30)
+	if c.Heating.Talpha != 2050 || c.Flue["N2"] != 719.25 {
+		t.Errorf("expected Talpha 2050 and flue N2 719.25; got %f and %f", c.Heating.Talpha, c.Flue["N2"])
+	}
+	if DefaultConfig().Flue["N2"] != 738.5 {
+		t.Errorf("apply modified the flue of its argument")
+	}
+	if err := s.validate(DefaultConfig()); err != nil {
+		t.Error(err)
+	}
+	s.Inputs["flue:Ar"] = []float64{1, 1}
+	if err := s.validate(DefaultConfig()); err == nil {
+		t.Error("expected an error for an unknown heating gas species")
+	}
+	delete(s.Inputs, "flue:Ar")
+	s.Inputs["l"] = []float64{12, 10}
+	if err := s.validate(DefaultConfig()); err == nil {
+		t.Error("expected an error for a scheduled tube length")
+	}
+	if _, err := ReadSchedule(strings.NewReader("time,Talpha\n0,2000\n")); err == nil {
+		t.Error("expected an error for a header that does not start with t")
+	}
+}
+
+func TestScheduled(t *testing.T) {
+	c := DefaultConfig()
+	c.Transient.Schedule = Schedule{
+		Times:  []float64{0, 10},
+		Inputs: map[string][]float64{"Talpha": {2000, 2100}, "voidage": {0.5, 1.5}},
+	}
+	m, err := newModel(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &scheduled{base: m, t: math.NaN()}
+	at, err := s.at(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if at.Heating.Talpha != 2020 {
+		t.Errorf("expected Talpha 2020 at 2 s; got %f", at.Heating.Talpha)
+	}
+	if again, _ := s.at(2); again != at {
+		t.Error("expected the model to be reused at the same time")
+	}
+	if _, err := s.at(8); err == nil || s.err == nil {
+		t.Error("expected an error for a voidage above one")
+	}
+}
+
+func TestSimulateTransient(t *testing.T) {
+	c := DefaultConfig()
+	c.Dispersion.Cells = 8
+	c.Transient.Duration, c.Transient.Interval = 600, 300
+	c.Transient.Schedule = Schedule{
+		Times:  []float64{0, 10},
+		Inputs: map[string][]float64{"Talpha": {2000, 2100}},
+	}
+	h, err := SimulateTransient(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Times) != 3 || h.Times[2] != 600 {
+		t.Fatalf("expected profiles at 0, 300 and 600 s; got %v", h.Times)
+	}
+	outlet := func(p *Profile) float64 { return p.T[len(p.T)-1] }
+	if !(outlet(h.Profiles[0]) < outlet(h.Profiles[1]) && outlet(h.Profiles[1]) < outlet(h.Profiles[2])) {
+		t.Errorf("expected the outlet temperature to rise after firing increases; got %f, %f, %f",
+			outlet(h.Profiles[0]), outlet(h.Profiles[1]), outlet(h.Profiles[2]))
+	}
+
+	// without a schedule the initial steady state persists
+	c.Transient.Schedule = Schedule{}
+	if h, err = SimulateTransient(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if Δ := math.Abs(outlet(h.Profiles[2]) - outlet(h.Profiles[0])); Δ > 0.01 {
+		t.Errorf("expected a steady outlet temperature; changed by %f K", Δ)
+	}
+
+	c.Events = []Event{ConversionAbove(0.5)}
+	if _, err := SimulateTransient(context.Background(), c); err == nil {
+		t.Error("expected an error for events in a transient simulation")
+	}
+
+	var buffer bytes.Buffer
+	if err := h.WriteCSV(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1+3*(c.Dispersion.Cells+1) {
+		t.Errorf("expected %d rows; got %d", 1+3*(c.Dispersion.Cells+1), len(records))
+	}
+}
@@ -0,0 +1,59 @@
+// Package units provides typed physical quantities, so that conversions
+// between the unit systems used by different correlations are explicit.
+// Quantities are stored in SI units and expressed in another unit with In,
+// in the same manner as time.Duration.
+//
+// The types are used where quantities cross between parts of the model, such
+// as the pressure handed to the rate laws. The state vector, the rate laws
+// and the thermodynamic correlations work in plain float64 in documented
+// units, since the solver and the jets see only float64.
+package units
+
+// Pressure is an absolute pressure, stored in pascals.
+type Pressure float64
+
+const (
+	Pa  Pressure = 1
+	KPa Pressure = 1000
+	Bar Pressure = 100000
+)
+
+// In returns p in multiples of unit.
+func (p Pressure) In(unit Pressure) float64 {
+	return float64(p / unit)
+}
+
+// Temperature is an absolute temperature, stored in kelvin.
+type Temperature float64
+
+// K is one kelvin.
+const K Temperature = 1
+
+// In returns t in multiples of unit.
+func (t Temperature) In(unit Temperature) float64 {
+	return float64(t / unit)
+}
+
+// MolarFlow is a molar flow rate, stored in mol/s.
+type MolarFlow float64
+
+// MolPerS is one mol/s.
+const MolPerS MolarFlow = 1
+
+// In returns f in multiples of unit.
+func (f MolarFlow) In(unit MolarFlow) float64 {
+	return float64(f / unit)
+}
+
+// Energy is stored in joules.
+type Energy float64
+
+const (
+	J  Energy = 1
+	KJ Energy = 1000
+)
+
+// In returns e in multiples of unit.
+func (e Energy) In(unit Energy) float64 {
+	return float64(e / unit)
+}
@@ -0,0 +1,32 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+func _compare(t *testing.T, res, expected float64) {
+	if math.Abs(res-expected) >= tolerance {
+		t.Errorf("incorrect conversion: expected %f; got %f", expected, res)
+	}
+}
+
+func TestPressure(t *testing.T) {
+	p := 2350 * KPa
+	_compare(t, p.In(Pa), 2350000)
+	_compare(t, p.In(Bar), 23.5)
+}
+
+func TestTemperature(t *testing.T) {
+	_compare(t, (823.15 * K).In(K), 823.15)
+}
+
+func TestMolarFlow(t *testing.T) {
+	_compare(t, (2.5 * MolPerS).In(MolPerS), 2.5)
+}
+
+func TestEnergy(t *testing.T) {
+	_compare(t, (2.5 * KJ).In(J), 2500)
+}
@@ -0,0 +1,155 @@
+package reactor
+
+import "math"
+
+// WallCorrelation selects the correlation for the bed-side wall heat transfer
+// coefficient, hw, from which U is found locally along the tube.
+type WallCorrelation string
+
+const (
+	// Leva gives hw·D/k = 0.813 Re^0.9 exp(-6 Dp/D) for a heated bed.
+	Leva WallCorrelation = "leva"
+	// LiFinlayson gives hw·Dp/k = 0.17 Re^0.79 for a bed of spheres.
+	LiFinlayson WallCorrelation = "li-finlayson"
+	// Dixon gives hw·Dp/k = (1 - 1.5 (D/Dp)^-1.5) Re^0.59 Pr^(1/3) for a bed
+	// of spheres.
+	Dixon WallCorrelation = "dixon"
+)
+
+// Wall describes the metal of the tube, whose inside diameter is Geometry.D.
+type Wall struct {
+	Thickness    float64 // m
+	Conductivity float64 // W/mK
+}
+
+// Resistances breaks down the resistance to heat transfer between the
+// heating gas and the process gas (m^2K/W, per unit inside wall area). Wall
+// and Outside are zero where the heating mode gives the temperature or flux
+// inside them.
+type Resistances struct {
+	Bed     float64 // from the wall into the bed
+	Wall    float64 // conduction through the metal
+	Outside float64 // from the heating gas to the outside of the wall
+}
+
+// Total returns the sum of the resistances, the reciprocal of U.
+func (r Resistances) Total() float64 {
+	return r.Bed + r.Wall + r.Outside
+}
+
+// wallCoefficient returns hw (W/m^2K) by correlation c from the Reynolds and
+// Prandtl numbers of the bed and the gas conductivity k (W/mK).
+func (m *model) wallCoefficient(c WallCorrelation, Re, Pr, k float64) float64 {
+	D, Dp := m.Geometry.D, m.Catalyst.Dp
+	switch c {
+	case Leva:
+		return 0.813 * math.Pow(Re, 0.9) * math.Exp(-6*Dp/D) * k / D
+	case LiFinlayson:
+		return 0.17 * math.Pow(Re, 0.79) * k / Dp
+	}
+	return (1 - 1.5*math.Pow(D/Dp, -1.5)) * math.Pow(Re, 0.59) * math.Cbrt(Pr) * k / Dp
+}
+
+// bedResistance returns the resistance from the inside of the wall to the
+// gas in the ring next to it (m^2K/W) by correlation c, from the properties
+// of that gas given by the thermo package.
+func (m *model) bedResistance(y []float64, c WallCorrelation) float64 {
+	n := m.n
+	ring := y[m.iP()-n-1 : m.iP()]
+	T := ring[n]
+	massFlow := m.mech.massFlow(ring) / 1000
+	capacity := m.mech.heatCapacity(T, ring)
+	μ, k := m.transport.Properties(ring[:n], T)
+	G := massFlow / (m.area * m.fraction(m.rings-1))
+	Re := G * m.Catalyst.Dp / μ
+	Pr := capacity / massFlow * μ / k
+
+	resistance := 1 / m.wallCoefficient(c, Re, Pr, k)
+	if m.rings > 1 {
+		// the outermost node sees the wall through half a ring of bed
+		resistance += m.width() / 2 / m.Radial.Conductivity
+	}
+	return resistance
+}
+
+// wallResistance returns the resistance to conduction through the metal
+// (m^2K/W, per unit inside wall area).
+func (m *model) wallResistance() float64 {
+	if m.Wall.Thickness == 0 {
+		return 0
+	}
+	R, Ro := m.Geometry.D/2, m.Geometry.D/2+m.Wall.Thickness
+	return R * math.Log(Ro/R) / m.Wall.Conductivity
+}
+
+// resistances returns the breakdown of 1/U for the state y by the selected
+// wall correlation.
+func (m *model) resistances(y []float64) Resistances {
+	r := Resistances{Bed: m.bedResistance(y, m.Heating.Correlation)}
+	if m.Heating.Mode == WallTemperature {
+		return r
+	}
+	r.Wall = m.wallResistance()
+	if m.Heating.Mode != Radiation {
+		r.Outside = m.outsideResistance()
+	}
+	return r
+}
+
+// outsideResistance returns the resistance from the heating gas to the
+// outside of the wall (m^2K/W, per unit inside wall area), or zero if
+// Heating.Outside is not given.
+func (m *model) outsideResistance() float64 {
+	if m.Heating.Outside == 0 {
+		return 0
+	}
+	R, Ro := m.Geometry.D/2, m.Geometry.D/2+m.Wall.Thickness
+	return R / Ro / m.Heating.Outside
+}
+
+// coefficient returns U (W/m^2K) for the state y, which is fixed unless a
+// wall correlation is selected.
+func (m *model) coefficient(y []float64) float64 {
+	if m.Heating.Correlation == "" || m.Heating.Adiabatic {
+		return m.U
+	}
+	return 1 / m.resistances(y).Total()
+}
+
+// wallTemperatures returns the temperatures of the inside and outside of the
+// tube wall (K) at catalyst mass w and state y. They follow from the flux
+// through the resistances of the bed and the metal, except that the inside
+// is given in WallTemperature heating and the outside, which absorbs the
+// radiation, in Radiation heating. The bed-side resistance is that of the
+// wall correlation or the radial bed. Otherwise U is fixed and the walls are
+// found consistently with it: from the heating gas side, through Outside and
+// the metal, or, under a HeatFlux, by taking U as the bed-side coefficient,
+// as it is with a WallTemperature.
+func (m *model) wallTemperatures(w float64, y []float64) (inner, outer float64) {
+	q := m.flux(w, y)
+	T := y[m.iP()-1]
+	switch {
+	case m.Heating.Adiabatic:
+		return T, T
+	case m.Heating.Mode == WallTemperature:
+		inner = m.Heating.Profile.At(m.z(w))
+		return inner, inner + q*m.wallResistance()
+	case m.Heating.Mode == Radiation:
+		_, outer = m.radiantFlux(y[m.iTα()], T, m.coefficient(y))
+		return outer - q*m.wallResistance(), outer
+	}
+	var bed float64
+	switch {
+	case m.Heating.Correlation != "":
+		bed = m.bedResistance(y, m.Heating.Correlation)
+	case m.rings > 1:
+		bed = 1/m.Radial.Hw + m.width()/2/m.Radial.Conductivity
+	case m.Heating.Mode == HeatFlux:
+		bed = 1 / m.U
+	default:
+		outer = y[m.iTα()] - q*m.outsideResistance()
+		return outer - q*m.wallResistance(), outer
+	}
+	inner = T + q*bed
+	return inner, inner + q*m.wallResistance()
+}
@@ -0,0 +1,87 @@
+package reactor
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestWallCoefficient(t *testing.T) {
+	c := DefaultConfig()
+	c.Catalyst.Dp = 1e-7 // a tube of vanishing particles
+	fine, err := newModel(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	D, Dp, k := c.Geometry.D, c.Catalyst.Dp, 0.1
+	// at Re and Pr of one, far from the wall effect of the particles, the
+	// Nusselt numbers reduce to the leading constants
+	for corr, expected := range map[WallCorrelation]float64{
+		Leva:        0.813 * k / D,
+		LiFinlayson: 0.17 * k / Dp,
+		Dixon:       k / Dp,
+	} {
+		if hw := fine.wallCoefficient(corr, 1, 1, k); math.Abs(hw-expected) > 1e-3*expected {
+			t.Errorf("%s: expected %g W/m^2K; got %g", corr, expected, hw)
+		}
+	}
+
+	m, err := newModel(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// hw rises as the power of Re of each correlation, and only Dixon's with Pr
+	for corr, exponent := range map[WallCorrelation]float64{Leva: 0.9, LiFinlayson: 0.79, Dixon: 0.59} {
+		ratio := m.wallCoefficient(corr, 2000, 0.7, k) / m.wallCoefficient(corr, 1000, 0.7, k)
+		if expected := math.Pow(2, exponent); math.Abs(ratio-expected) > 1e-9 {
+			t.Errorf("%s: expected hw to rise by %f when Re doubles; got %f", corr, expected, ratio)
+		}
+		ratio = m.wallCoefficient(corr, 1000, 5.6, k) / m.wallCoefficient(corr, 1000, 0.7, k)
+		if expected := map[bool]float64{true: 2, false: 1}[corr == Dixon]; math.Abs(ratio-expected) > 1e-9 {
+			t.Errorf("%s: expected hw to change by %f when Pr rises eightfold; got %f", corr, expected, ratio)
+		}
+	}
+
+	// Dixon's wall term vanishes in a tube of 1.5^(2/3) particle diameters,
+	// and Leva's falls as exp(-6 Dp/D)
+	c = DefaultConfig()
+	c.Geometry.D = math.Pow(1.5, 2.0/3) * c.Catalyst.Dp
+	narrow, err := newModel(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hw := narrow.wallCoefficient(Dixon, 1000, 0.7, k); math.Abs(hw) > 1e-9 {
+		t.Errorf("expected no Dixon coefficient at D/Dp = 1.31; got %g", hw)
+	}
+	leva := narrow.wallCoefficient(Leva, 1000, 0.7, k) * c.Geometry.D / (fine.wallCoefficient(Leva, 1000, 0.7, k) * D)
+	if expected := math.Exp(-6 / math.Pow(1.5, 2.0/3)); math.Abs(leva-expected) > 1e-3*expected {
+		t.Errorf("expected Leva's Nusselt number to fall to %f of its open-tube value; got %f", expected, leva)
+	}
+}
+
+func TestSimulateWallCorrelation(t *testing.T) {
+	for _, mode := range []HeatingMode{HeatingGas, WallTemperature} {
+		c := DefaultConfig()
+		c.Heating.Correlation = Dixon
+		c.Heating.Mode = mode
+		c.Heating.Profile = Table{Z: []float64{0, 15}, Values: []float64{1000, 1150}}
+		p, err := Simulate(context.Background(), c)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		for k, r := range p.Resistances {
+			if r.Bed <= 0 || (r.Wall > 0) != (mode == HeatingGas) || (r.Outside > 0) != (mode == HeatingGas) {
+				t.Fatalf("%s: step %d: unexpected resistances %+v", mode, k, r)
+			}
+			if q := (p.Talpha[k] - p.T[k]) / r.Total(); math.Abs(q-p.WallFlux[k]) > 1e-6*math.Abs(q) {
+				t.Fatalf("%s: step %d: expected a wall flux of %f; got %f", mode, k, q, p.WallFlux[k])
+			}
+		}
+		if len(p.Resistances) != len(p.W) {
+			t.Errorf("%s: expected resistances at all %d steps; got %d", mode, len(p.W), len(p.Resistances))
+		}
+		if p.EnergyClosure > 1e-3 {
+			t.Errorf("%s: poor energy closure: %e", mode, p.EnergyClosure)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package reactor
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cpmech/gosl/ode"
+)
+
+// Zone is a length of tube with its own diameter and packing. Zero fields of
+// D and Catalyst keep those of the Config, as does a nil Kinetics.Factors.
+type Zone struct {
+	Length   float64 // m
+	D        float64 // tube diameter (m)
+	Catalyst Catalyst
+	Kinetics Kinetics
+	Activity float64 // multiplies the rates of the catalyst; zero leaves them unchanged
+	Inert    bool    // packing without catalyst, such as ceramic balls
+}
+
+// ReadZones reads zones, from the process inlet, from CSV with a header row
+// naming the columns length, D, density, voidage, Dp, activity and inert, of
+// which only length is required.
+func ReadZones(r io.Reader) ([]Zone, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("reactor: zones need a header and at least one row")
+	}
+	var zones []Zone
+	for i, record := range records[1:] {
+		var z Zone
+		for j, field := range record {
+			column := strings.ToLower(strings.TrimSpace(records[0][j]))
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if column == "inert" {
+				if z.Inert, err = strconv.ParseBool(field); err != nil {
+					return nil, fmt.Errorf("reactor: row %d of zones: %v", i+2, err)
+				}
+				continue
+			}
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("reactor: row %d of zones: %v", i+2, err)
+			}
+			switch column {
+			case "length":
+				z.Length = v
+			case "d":
+				z.D = v
+			case "density":
+				z.Catalyst.Density = v
+			case "voidage":
+				z.Catalyst.Voidage = v
+			case "dp":
+				z.Catalyst.Dp = v
+			case "activity":
+				z.Activity = v
+			default:
+				return nil, fmt.Errorf("reactor: unknown zone column %q", column)
+			}
+		}
+		zones = append(zones, z)
+	}
+	return zones, nil
+}
+
+// config returns the configuration of a tube made only of zone z.
+func (z Zone) config(c Config, reactions int) Config {
+	c.Zones = nil
+	c.Geometry.Length = z.Length
+	if z.D != 0 {
+		c.Geometry.D = z.D
+	}
+	if z.Catalyst.Density != 0 {
+		c.Catalyst.Density = z.Catalyst.Density
+	}
+	if z.Catalyst.Voidage != 0 {
+		c.Catalyst.Voidage = z.Catalyst.Voidage
+	}
+	if z.Catalyst.Dp != 0 {
+		c.Catalyst.Dp = z.Catalyst.Dp
+	}
+	if z.Catalyst.HeatCapacity != 0 {
+		c.Catalyst.HeatCapacity = z.Catalyst.HeatCapacity
+	}
+	if z.Kinetics.Factors != nil {
+		c.Kinetics = z.Kinetics
+	}
+	activity := z.Activity
+	if activity == 0 {
+		activity = 1
+	}
+	if z.Inert {
+		activity = 0
+	}
+	factors := make([]float64, reactions)
+	for j := range factors {
+		factors[j] = activity
+		if c.Kinetics.Factors != nil {
+			factors[j] *= c.Kinetics.Factors[j]
+		}
+	}
+	c.Kinetics.Factors = factors
+	return c
+}
+
+// zoned builds the models of the zones of m, which start at their catalyst
+// mass and position along the whole tube, and sets the length and catalyst
+// mass of m to those of the whole tube.
+func (m *model) zoned() error {
+	switch m.Bed {
+	case "", PlugFlow, Radial2D, Heterogeneous:
+	default:
+		return fmt.Errorf("reactor: zones need a plug flow, radial or heterogeneous bed, not %q", m.Bed)
+	}
+	m.Geometry.Length, m.W = 0, 0
+	for i, z := range m.Zones {
+		if z.Length <= 0 || z.Activity < 0 {
+			return fmt.Errorf("reactor: zone %d needs a positive length and an activity that is not negative", i+1)
+		}
+		if f := z.Kinetics.Factors; f != nil && len(f) != len(m.mech.reactions) {
+			return fmt.Errorf("reactor: zone %d has %d kinetic factors for %d reactions", i+1, len(f), len(m.mech.reactions))
+		}
+		zone, err := newModel(z.config(m.Config, len(m.mech.reactions)))
+		if err != nil {
+			return fmt.Errorf("reactor: zone %d: %v", i+1, err)
+		}
+		zone.wStart, zone.zStart = m.W, m.Geometry.Length
+		m.zones = append(m.zones, zone)
+		m.Geometry.Length += z.Length
+		m.W += zone.W
+	}
+	return nil
+}
+
+// zone returns the model of the zone holding catalyst mass w.
+func (m *model) zone(w float64) *model {
+	for _, zone := range m.zones {
+		if w < zone.wStart+zone.W {
+			return zone
+		}
+	}
+	return m.zones[len(m.zones)-1]
+}
+
+// integrateZones solves the zones in turn from the inlet state y0. The first
+// step of each zone repeats the last of the one before, at the same catalyst
+// mass, so that properties which jump at the boundary are seen on both sides.
+// The zones after one in which an event stops the tube are not solved.
+func (m *model) integrateZones(ctx context.Context, y0 []float64) (wValues []float64, yValues [][]float64, err error) {
+	return m.solveZones(ctx, y0, m.stopper(), func(i int) (ode.Func, ode.JacF) {
+		return m.zones[i].ODEs, m.zones[i].jacobian()
+	})
+}
+
+// solveZones integrates the system of each zone, given by its index, in turn
+// from y0 as integrateZones does.
+func (m *model) solveZones(ctx context.Context, y0 []float64, stop *stopper, system func(i int) (ode.Func, ode.JacF)) (wValues []float64, yValues [][]float64, err error) {
+	y := append([]float64(nil), y0...)
+	yValues = make([][]float64, len(y))
+	for i, zone := range m.zones {
+		if stop != nil && stop.fired {
+			break
+		}
+		fcn, jac := system(i)
+		w, states, err := m.solve(ctx, fcn, jac, y, zone.wStart, zone.wStart+zone.W, stop)
+		if err != nil {
+			return nil, nil, err
+		}
+		wValues = append(wValues, w...)
+		for i := range y {
+			yValues[i] = append(yValues[i], states[i]...)
+			y[i] = states[i][len(w)-1]
+		}
+	}
+	return wValues, yValues, nil
+}
+
+// zonedProfile assembles the profiles of the zones, split where the
+// catalyst mass restarts, into a Profile of the whole tube, which may end in
+// any of them.
+func (m *model) zonedProfile(wValues []float64, states [][]float64) *Profile {
+	var p *Profile
+	var duty float64
+	start := 0
+	for _, zone := range m.zones {
+		if start >= len(wValues) {
+			break
+		}
+		end := start + 1
+		for end < len(wValues) && wValues[end] > wValues[end-1] {
+			end++
+		}
+		segment := make([][]float64, len(states))
+		for i := range states {
+			segment[i] = states[i][start:end]
+		}
+		q := zone.profile(wValues[start:end], segment)
+		duty += zone.duty(wValues[start:end], segment)
+		if p == nil {
+			p = q
+		} else {
+			p.append(q)
+		}
+		start = end
+	}
+
+	yValues := states
+	if m.rings > 1 {
+		yValues = m.mixed(states)
+	}
+	n, last := m.n, len(wValues)-1
+	inlet, outlet := make([]float64, n), make([]float64, n)
+	for i := range inlet {
+		inlet[i], outlet[i] = yValues[i][0], yValues[i][last]
+	}
+	p.ElementClosure, p.ClosureElement = m.mech.elementClosure(yValues)
+	p.EnergyClosure = energyClosure(m.mech.species, inlet, outlet, yValues[n][0], yValues[n][last], duty)
+	return p
+}
@@ -0,0 +1,70 @@
+package reactor
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadZones(t *testing.T) {
+	zones, err := ReadZones(strings.NewReader("length, D, density, inert\n0.5, , 1200, true\n14.5, 0.12, , false\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 2 || zones[0].Catalyst.Density != 1200 || !zones[0].Inert || zones[1].Inert || zones[1].D != 0.12 || zones[1].Length != 14.5 {
+		t.Errorf("incorrect zones: %+v", zones)
+	}
+	for _, bad := range []string{"length\n", "length, colour\n1, 2\n", "length, inert\n1, perhaps\n"} {
+		if _, err := ReadZones(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestSimulateZones(t *testing.T) {
+	uniform, err := Simulate(context.Background(), DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// zones that repeat the uniform tube recover it
+	c := DefaultConfig()
+	c.Zones = []Zone{{Length: 5}, {Length: 10}}
+	p, err := Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, res := uniform.T[len(uniform.T)-1], p.T[len(p.T)-1]
+	if math.Abs(res-expected) > 0.01 {
+		t.Errorf("expected an outlet temperature of %f K; got %f", expected, res)
+	}
+	if last := len(p.W) - 1; math.Abs(p.W[last]-uniform.W[len(uniform.W)-1]) > 1e-9 {
+		t.Errorf("expected %f kg of catalyst; got %f", uniform.W[len(uniform.W)-1], p.W[last])
+	}
+
+	// no reaction in an inert inlet zone of denser packing
+	c.Zones = []Zone{{Length: 1, Catalyst: Catalyst{Density: 1400, Voidage: 0.4}, Inert: true}, {Length: 14, D: 0.12}}
+	if p, err = Simulate(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	boundary := 1400 * math.Pi * 0.11 * 0.11 / 4
+	for k, w := range p.W {
+		if w > boundary+1e-9 {
+			break
+		}
+		if CH4 := p.Flows["CH4"][k]; math.Abs(CH4-p.Flows["CH4"][0]) > 1e-9 {
+			t.Fatalf("step %d: expected no methane to react in the inert zone; got %f mol/s", k, CH4)
+		}
+	}
+	if p.ElementClosure > 1e-9 || p.EnergyClosure > 1e-3 {
+		t.Errorf("poor closure: element %e; energy %e", p.ElementClosure, p.EnergyClosure)
+	}
+	if len(p.Reactions[0].Rate) != len(p.W) || len(p.OuterWall) != len(p.W) {
+		t.Errorf("expected every series to cover the %d steps", len(p.W))
+	}
+
+	c.Bed = AxialDispersion
+	if _, err := Simulate(context.Background(), c); err == nil {
+		t.Errorf("expected an error for zones in a dispersion bed")
+	}
+}